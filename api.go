@@ -12,70 +12,540 @@ package groupme
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha1"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 // BaseURL is the base URL of which all API endpoint are built from.
 const BaseURL = "https://api.groupme.com/v3"
 
+// ImageServiceURL is the base URL of the image service which hosts all images
+// attached to messages.
+const ImageServiceURL = "https://image.groupme.com"
+
 // Client is the interface that implements the Do method for making API requests.
 type Client interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
 type client struct {
-	ctx         context.Context
-	client      *http.Client
+	ctx       context.Context
+	client    *http.Client
+	baseURL   string
+	imageURL  string
+	pushURL   string
+	userAgent string
+	header    http.Header
+	logger    func(req *http.Request, resp *http.Response, err error)
+	limiter   RateLimiter
+	strict    bool
+
+	mu          sync.Mutex
 	accessToken string
+	rateLimit   RateLimit
+}
+
+// A ClientOption configures a client created by NewClient.
+type ClientOption func(*client)
+
+// WithBaseURL sets the base URL that all API endpoints are built from, e.g. to
+// use a test server or a reverse proxy. By default BaseURL is used. Any
+// trailing slash is removed.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithImageServiceURL sets the base URL of the image service used by the
+// ImageService. By default ImageServiceURL is used. Any trailing slash is
+// removed.
+func WithImageServiceURL(imageURL string) ClientOption {
+	return func(c *client) {
+		c.imageURL = strings.TrimRight(imageURL, "/")
+	}
+}
+
+// WithPushURL sets the URL of the push service used by the PushService. By
+// default PushURL is used.
+func WithPushURL(pushURL string) ClientOption {
+	return func(c *client) {
+		c.pushURL = pushURL
+	}
+}
+
+// WithUserAgent sets the 'User-Agent' header sent with every request, which
+// by default is the Go HTTP client's.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithLogger sets a function called after every request made by the client,
+// e.g. to log requests at a debug level. The request given to the logger has
+// the access token removed from its URL and headers, as does the request the
+// response refers to. The response is nil if the request failed, and its body
+// must not be read by the logger.
+func WithLogger(logger func(req *http.Request, resp *http.Response, err error)) ClientOption {
+	return func(c *client) {
+		c.logger = logger
+	}
+}
+
+// WithRateLimiter sets a rate limiter that is waited on before every request,
+// except those made by a PushService, throttling requests so the server's rate
+// limit isn't reached. If the request's context is done while waiting its error
+// is returned. See NewDefaultRateLimiter for a limiter suitable for most uses.
+func WithRateLimiter(limiter RateLimiter) ClientOption {
+	return func(c *client) {
+		c.limiter = limiter
+	}
+}
+
+// WithHeader adds a header sent with every request, e.g. one required by a
+// proxy. It may be given multiple times, including for the same key. Headers
+// set by the request itself take precedence, and the 'X-Access-Token' header
+// cannot be set. Setting 'Content-Type' changes the default used for requests
+// that don't set their own.
+func WithHeader(key, value string) ClientOption {
+	return func(c *client) {
+		if c.header == nil {
+			c.header = make(http.Header)
+		}
+		c.header.Add(key, value)
+	}
+}
+
+// WithStrictValidation enables checking the JSON body of requests that create
+// or update resources, such as posting a message, against the fields
+// documented by the API before they are sent. Unknown fields, missing required
+// fields, and values of the wrong type or length cause Do to return an error
+// instead of making the request. It is intended for use during development.
+func WithStrictValidation() ClientOption {
+	return func(c *client) {
+		c.strict = true
+	}
+}
+
+// WithTimeout sets a limit on the time taken by each request, including reading
+// the response body. Unlike a context deadline, which applies to a request or
+// a sequence of requests as chosen by the caller, the timeout is a hard ceiling
+// applied to every request made by the client. Requests made by a PushService
+// are exempt since its long polls are expected to outlast typical timeouts,
+// instead they are limited by the timeout advised by the push service.
+func WithTimeout(d time.Duration) ClientOption {
+	return withHTTPClient(func(httpClient *http.Client) {
+		httpClient.Timeout = d
+	})
+}
+
+// WithTransport sets the transport used to make requests, e.g. one wrapping
+// http.DefaultTransport to record metrics. It can be combined with WithTimeout
+// in either order.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return withHTTPClient(func(httpClient *http.Client) {
+		httpClient.Transport = transport
+	})
 }
 
-// NewClient creates a client with the given context and access token.
-func NewClient(ctx context.Context, accessToken string) Client {
+// WithConnectionPool sets the maximum number of idle connections kept open for
+// reuse, in total and per host, e.g. to avoid reconnecting when making many
+// requests at once. It configures a copy of the current transport if it is an
+// *http.Transport and is ignored otherwise, e.g. if given after WithTransport
+// with a wrapping transport. If given before WithTransport the transport set by
+// WithTransport is used instead of the configured one.
+func WithConnectionPool(maxIdle, maxIdlePerHost int) ClientOption {
+	return withHTTPClient(func(httpClient *http.Client) {
+		var transport *http.Transport
+		switch t := httpClient.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = t.Clone()
+		default:
+			return
+		}
+		transport.MaxIdleConns = maxIdle
+		transport.MaxIdleConnsPerHost = maxIdlePerHost
+		httpClient.Transport = transport
+	})
+}
+
+// withHTTPClient returns an option that configures the client's HTTP client
+// using configure.
+func withHTTPClient(configure func(httpClient *http.Client)) ClientOption {
+	return func(c *client) {
+		// Copy the HTTP client so a shared one, e.g. http.DefaultClient, isn't
+		// modified
+		httpClient := *c.client
+		configure(&httpClient)
+		c.client = &httpClient
+	}
+}
+
+// NewClient creates a client with the given context and access token. The
+// context is used for any request that does not carry its own context, all
+// service methods set the context given to them on their requests.
+func NewClient(ctx context.Context, accessToken string, options ...ClientOption) Client {
+	return NewClientWithHTTP(ctx, accessToken, http.DefaultClient, options...)
+}
+
+// NewClientWithHTTP creates a client like NewClient but makes requests using the
+// given HTTP client, e.g. one with a timeout or custom transport. If httpClient
+// is nil http.DefaultClient is used.
+func NewClientWithHTTP(ctx context.Context, accessToken string, httpClient *http.Client, options ...ClientOption) Client {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	return &client{
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	c := &client{
 		ctx:         ctx,
-		client:      http.DefaultClient,
+		client:      httpClient,
 		accessToken: accessToken,
+		baseURL:     BaseURL,
+		imageURL:    ImageServiceURL,
+		pushURL:     PushURL,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// BaseURL returns the base URL that all API endpoints are built from.
+func (c *client) BaseURL() string { return c.baseURL }
+
+// ImageServiceURL returns the base URL of the image service.
+func (c *client) ImageServiceURL() string { return c.imageURL }
+
+// PushURL returns the URL of the push service.
+func (c *client) PushURL() string { return c.pushURL }
+
+// AccessTokenSetter is implemented by clients whose access token can be changed
+// after they are created, including those returned by NewClient.
+type AccessTokenSetter interface {
+	SetAccessToken(accessToken string)
+}
+
+// SetAccessToken replaces the access token used by all subsequent requests,
+// e.g. after the previous token expired or was revoked. It is safe to call
+// while requests are being made. A PushService has its own copy of the token
+// and must be recreated to use the new one.
+func (c *client) SetAccessToken(accessToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken = accessToken
+}
+
+// ContextBinder is implemented by clients that can be bound to a different
+// context, including those returned by NewClient.
+type ContextBinder interface {
+	WithContext(ctx context.Context) Client
+}
+
+// WithContext returns a new client that uses the given context for requests
+// without their own context, e.g. to scope a sequence of calls to the context
+// of an incoming request. The original client is unchanged. Both clients share
+// everything else, including the access token and rate limit state.
+func (c *client) WithContext(ctx context.Context) Client {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &contextClient{client: c, ctx: ctx}
+}
+
+// A contextClient is a client bound to a different context, see WithContext.
+type contextClient struct {
+	*client
+	ctx context.Context
+}
+
+// Do makes an API request like the client it was created from, using its own
+// context if the request doesn't have one.
+func (c *contextClient) Do(req *http.Request) (resp *http.Response, err error) {
+	if req.Context() == context.Background() {
+		req = req.WithContext(c.ctx)
+	}
+	return c.client.Do(req)
+}
+
+// A RateLimit is the rate limit state reported by the server in the
+// 'X-RateLimit-*' headers of a response.
+type RateLimit struct {
+	// Limit is the number of requests allowed in the current period.
+	Limit int
+
+	// Remaining is the number of requests remaining in the current period.
+	Remaining int
+
+	// Reset is the time the current period ends.
+	Reset time.Time
+}
+
+// RateLimitReporter is implemented by clients that record the rate limit
+// reported by the most recent response, including those returned by NewClient.
+type RateLimitReporter interface {
+	LastRateLimit() RateLimit
+}
+
+// LastRateLimit returns the rate limit reported by the most recent response
+// that included rate limit headers.
+func (c *client) LastRateLimit() RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimit
+}
+
+// parseRateLimit parses the rate limit headers of a response, returning false
+// if there are none.
+func parseRateLimit(h http.Header) (rl RateLimit, ok bool) {
+	if h.Get("X-RateLimit-Limit") == "" && h.Get("X-RateLimit-Remaining") == "" {
+		return
+	}
+	rl.Limit, _ = strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	rl.Remaining, _ = strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if sec, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(sec, 0)
+	}
+	return rl, true
+}
+
+// ErrEmptyResponse is returned when a successful response has no body, or its
+// envelope has no response, but the method requires one to return a value.
+// Methods that don't return a value, e.g. GroupsService.Destroy, don't read the
+// body and accept an empty one.
+var ErrEmptyResponse = errors.New("response is empty")
+
+// decodeResponse decodes the JSON body of a successful response into v, which
+// must be an envelope with a 'response' field. ErrEmptyResponse is returned if
+// the body or its response is empty.
+func decodeResponse(body io.Reader, v interface{}) (err error) {
+	var b []byte
+	b, err = ioutil.ReadAll(body)
+	if err != nil {
+		return
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return ErrEmptyResponse
+	}
+
+	var env struct {
+		Response json.RawMessage `json:"response"`
+	}
+	if err = json.Unmarshal(b, &env); err != nil {
+		return
+	}
+	if len(env.Response) == 0 || string(env.Response) == "null" {
+		return ErrEmptyResponse
+	}
+	return json.Unmarshal(b, v)
+}
+
+// redactRequest returns a copy of the request with the access token removed,
+// suitable for logging.
+func redactRequest(req *http.Request) *http.Request {
+	r := req.Clone(req.Context())
+	r.URL.RawQuery = redactQuery(r.URL.RawQuery)
+	r.Header.Del("X-Access-Token")
+	return r
+}
+
+// redactError removes the access token from the URL of an error returned by
+// an HTTP client, which otherwise includes it.
+func redactError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	redacted := *urlErr
+	if u, parseErr := url.Parse(redacted.URL); parseErr == nil {
+		u.RawQuery = redactQuery(u.RawQuery)
+		redacted.URL = u.String()
+	}
+	return &redacted
+}
+
+func redactQuery(rawQuery string) string {
+	params, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return ""
+	}
+	params.Del("token")
+	return params.Encode()
+}
+
+// baseURL returns the base URL used by the given client. Clients may override
+// the default BaseURL by implementing a BaseURL method.
+func baseURL(c Client) string {
+	if b, ok := c.(interface{ BaseURL() string }); ok {
+		return b.BaseURL()
+	}
+	return BaseURL
+}
+
+// imageServiceURL returns the image service URL used by the given client.
+// Clients may override the default ImageServiceURL by implementing an
+// ImageServiceURL method.
+func imageServiceURL(c Client) string {
+	if i, ok := c.(interface{ ImageServiceURL() string }); ok {
+		return i.ImageServiceURL()
+	}
+	return ImageServiceURL
+}
+
+// pushURL returns the push service URL used by the given client. Clients may
+// override the default PushURL by implementing a PushURL method.
+func pushURL(c Client) string {
+	if p, ok := c.(interface{ PushURL() string }); ok {
+		return p.PushURL()
 	}
+	return PushURL
 }
 
-// Do makes an API request correctly setting the 'Content-Type' header to
-// 'application/json' and the 'token' URL parameter.
+// Do makes an API request correctly setting the 'token' URL parameter and the
+// 'X-Access-Token' header. The 'Content-Type' header is set to
+// 'application/json' unless the request has already set it.
 func (c *client) Do(req *http.Request) (resp *http.Response, err error) {
-	// Set the client context
-	req = req.WithContext(c.ctx)
+	// Set the client context if the request doesn't have its own
+	if req.Context() == context.Background() {
+		req = req.WithContext(c.ctx)
+	}
+
+	c.mu.Lock()
+	accessToken := c.accessToken
+	c.mu.Unlock()
 
 	// Set the access token URL parameter
 	params := req.URL.Query()
-	params.Set("token", c.accessToken)
+	params.Set("token", accessToken)
 	req.URL.RawQuery = params.Encode()
 
+	// Set the access token header, which is required by the image service
+	req.Header.Set("X-Access-Token", accessToken)
+
+	// Set any custom headers the request hasn't set itself
+	for key, values := range c.header {
+		if key == "X-Access-Token" || req.Header.Get(key) != "" {
+			continue
+		}
+		req.Header[key] = append([]string(nil), values...)
+	}
+
 	// Set the content-type header
-	req.Header.Set("Content-Type", "application/json")
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Set the user agent header
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	// Check the request body
+	if c.strict {
+		err = validateRequest(req, c.baseURL)
+		if err != nil {
+			return
+		}
+	}
+
+	// Push service requests include long polls, which would each use up the
+	// rate limit and fail if they took longer than the client timeout, so they
+	// are exempt from both and set their own deadlines
+	push := isPushRequest(req.Context())
+
+	// Wait until the request is allowed
+	if c.limiter != nil && !push {
+		err = c.limiter.Wait(req.Context())
+		if err != nil {
+			return
+		}
+	}
+
+	// Log the request once done
+	if c.logger != nil {
+		defer func() {
+			redacted := redactRequest(req)
+
+			// The response refers to the request, so give the logger a copy
+			// referring to the redacted one instead
+			logResp := resp
+			if resp != nil {
+				r := *resp
+				r.Request = redacted
+				logResp = &r
+			}
+			c.logger(redacted, logResp, redactError(err))
+		}()
+	}
 
 	// Do the request
-	resp, err = c.client.Do(req)
+	httpClient := c.client
+	if push && httpClient.Timeout != 0 {
+		withoutTimeout := *httpClient
+		withoutTimeout.Timeout = 0
+		httpClient = &withoutTimeout
+	}
+	resp, err = httpClient.Do(req)
 	if err != nil {
 		return
 	}
 
+	// Record the rate limit
+	if rl, ok := parseRateLimit(resp.Header); ok {
+		c.mu.Lock()
+		c.rateLimit = rl
+		c.mu.Unlock()
+	}
+
 	// Check for any errors
 	if resp.StatusCode >= 400 {
-		var apiErr Error
-		err = json.NewDecoder(resp.Body).Decode(&apiErr)
-		if err != nil {
-			return
-		}
-		resp.Body.Close()
-		err = apiErr
+		defer resp.Body.Close()
+		err = CheckResponse(resp)
+	}
+
+	return
+}
+
+// CheckResponse returns an Error built from the response if it has an error
+// status code, reading its body. It is used by the client returned by NewClient
+// and is exported for other clients, e.g. fakes used in tests, so their errors
+// match.
+func CheckResponse(resp *http.Response) (err error) {
+	if resp.StatusCode < 400 {
+		return
+	}
+
+	apiErr := Error{StatusCode: resp.StatusCode}
+	apiErr.RawBody, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return
 	}
 
+	// Fall back to an error built from the status code if the body isn't a
+	// JSON error message, e.g. an HTML error page or an empty body
+	if json.Unmarshal(apiErr.RawBody, &apiErr) != nil {
+		apiErr.Meta.Code = resp.StatusCode
+		apiErr.Meta.Errors = nil
+	}
+	err = apiErr
 	return
 }
 
@@ -86,25 +556,117 @@ type Error struct {
 		Errors []string `json:"errors"`
 	} `json:"meta"`
 	Response struct{} `json:"response"`
+
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int `json:"-"`
+
+	// RawBody is the unparsed body of the response.
+	RawBody []byte `json:"-"`
 }
 
 func (err Error) Error() string {
+	if len(err.Meta.Errors) == 0 {
+		return fmt.Sprintf("%d %s", err.StatusCode, http.StatusText(err.StatusCode))
+	}
 	return fmt.Sprintf("%+v", err.Meta.Errors)
 }
 
+// Errors returned by Error.Unwrap for common status codes, which allows them to
+// be checked using errors.Is.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrRateLimited  = errors.New("rate limited")
+)
+
+// Unwrap returns the error matching the status code of the response, or nil if
+// there isn't one.
+func (err Error) Unwrap() error {
+	switch err.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// A BatchError is returned by batch methods, e.g. LikesService.LikeAll, when
+// some of their operations fail. Each failure is kept keyed by the ID of the
+// item it failed for. The failures are also returned by Unwrap, so with Go 1.20
+// or later they can be checked using errors.Is and errors.As; earlier versions
+// don't look inside a BatchError, use Errors instead.
+type BatchError struct {
+	// Op is the method that failed, e.g. "LikesService.LikeAll".
+	Op string
+
+	// Total is the total number of operations in the batch.
+	Total int
+
+	// Errors are the errors of the failed operations keyed by item ID.
+	Errors map[string]error
+}
+
+func (err *BatchError) Error() string {
+	ids := err.ids()
+	msg := fmt.Sprintf("%s: %d of %d failed", err.Op, len(ids), err.Total)
+	if len(ids) > 0 {
+		msg += fmt.Sprintf(": %s: %v", ids[0], err.Errors[ids[0]])
+		if len(ids) > 1 {
+			msg += fmt.Sprintf(" (and %d more)", len(ids)-1)
+		}
+	}
+	return msg
+}
+
+// Unwrap returns the errors of the failed operations ordered by item ID, with
+// numeric IDs in numeric order.
+func (err *BatchError) Unwrap() []error {
+	ids := err.ids()
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		errs[i] = err.Errors[id]
+	}
+	return errs
+}
+
+func (err *BatchError) ids() []string {
+	ids := make([]string, 0, len(err.Errors))
+	for id := range err.Errors {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return compareIDs(ids[i], ids[j]) < 0 })
+	return ids
+}
+
 // GroupsService implements all the methods needed to access the groups endpoints.
 type GroupsService interface {
-	Index(options *GroupsIndexOptions) (groups []Group, err error)
-	Show(id string) (group Group, err error)
-	Former() (groups []Group, err error)
-	Create(g *Group) (group Group, err error)
-	Update(id string, g *Group) (group Group, err error)
-	Destroy(id string) (err error)
-	Join(id string, shareToken string) (group Group, err error)
-	Rejoin(id string) (group Group, err error)
+	Index(ctx context.Context, options *GroupsIndexOptions) (groups []Group, err error)
+	IndexAll(ctx context.Context, options *GroupsIndexOptions) (groups []Group, err error)
+	IndexPage(ctx context.Context, options *GroupsIndexOptions) (page GroupsPage, err error)
+	Show(ctx context.Context, id string, options *GroupsShowOptions) (group Group, err error)
+	Members(ctx context.Context, id string) (members []Member, err error)
+	ShowMany(ctx context.Context, ids []string) (groups map[string]Group, err error)
+	Former(ctx context.Context) (groups []Group, err error)
+	Create(ctx context.Context, g *Group, options *GroupsCreateOptions) (group Group, err error)
+	CreateWithAvatar(ctx context.Context, g *Group, avatar io.Reader, contentType string, options *GroupsCreateOptions) (group Group, err error)
+	Update(ctx context.Context, id string, g *Group) (group Group, err error)
+	Destroy(ctx context.Context, id string) (err error)
+	Join(ctx context.Context, id string, shareToken string) (group Group, err error)
+	Rejoin(ctx context.Context, id string) (group Group, err error)
+	IsFormer(ctx context.Context, id string) (former bool, err error)
 	// TODO(jlubawy): implement ChangeOwners
 }
 
+// ErrNotFormerGroup is returned by GroupsService.Rejoin when the group is not
+// one of the user's former groups.
+var ErrNotFormerGroup = errors.New("GroupsService.Rejoin: not a former group")
+
 type groupsService struct {
 	client Client
 }
@@ -122,29 +684,58 @@ type GroupsIndexOptions struct {
 	// request and the server default value is used.
 	Offset int
 
+	// Page is the page number to request, starting at one. If set it takes
+	// precedence over Offset.
+	Page int
+
 	// Limit limits the number of groups returned by the index request. If set
 	// to zero no parameter is sent in the request and the server default value
 	// is used.
 	Limit int
 
 	// Omit is a slice of strings sent as a comma-separated string in the request.
+	// Each must be one of the Omit constants, e.g. OmitMemberships.
 	Omit []string
 }
 
+// Values of GroupsIndexOptions.Omit and GroupsShowOptions.Omit for omitting
+// fields from returned groups.
+const (
+	OmitMemberships = "memberships"
+)
+
+// validateOmit checks that each omit value is known.
+func validateOmit(omit []string) error {
+	for _, o := range omit {
+		switch o {
+		case OmitMemberships:
+		default:
+			return fmt.Errorf("unknown omit value '%s'", o)
+		}
+	}
+	return nil
+}
+
 // Index lists the authenticated user's active groups.
-func (s *groupsService) Index(options *GroupsIndexOptions) (groups []Group, err error) {
+func (s *groupsService) Index(ctx context.Context, options *GroupsIndexOptions) (groups []Group, err error) {
 	if options == nil {
 		options = new(GroupsIndexOptions)
 	}
+	if err = validateOmit(options.Omit); err != nil {
+		err = fmt.Errorf("GroupsService.Index: %v", err)
+		return
+	}
 
 	var req *http.Request
-	req, err = http.NewRequest(http.MethodGet, BaseURL+"/groups", nil)
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+"/groups", nil)
 	if err != nil {
 		return
 	}
 
 	params := req.URL.Query()
-	if options.Offset != 0 {
+	if options.Page != 0 {
+		params.Set("page", strconv.Itoa(options.Page))
+	} else if options.Offset != 0 {
 		params.Set("page", strconv.Itoa(options.Offset+1))
 	}
 	if options.Limit != 0 {
@@ -165,7 +756,7 @@ func (s *groupsService) Index(options *GroupsIndexOptions) (groups []Group, err
 	var respEnv struct {
 		Groups []Group `json:"response"`
 	}
-	err = json.NewDecoder(resp.Body).Decode(&respEnv)
+	err = decodeResponse(resp.Body, &respEnv)
 	if err != nil {
 		return
 	}
@@ -173,10 +764,116 @@ func (s *groupsService) Index(options *GroupsIndexOptions) (groups []Group, err
 	return
 }
 
+// groupsIndexAllMaxPages is the maximum number of pages requested by IndexAll,
+// in case the server never returns a short page.
+const groupsIndexAllMaxPages = 1000
+
+// IndexAll lists all of the authenticated user's active groups by requesting
+// pages until an empty or short page is returned. Limit is used as the page
+// size, and the pages start at Page or Offset. If there are still more pages
+// after 1000 pages the groups listed so far are returned with an error.
+func (s *groupsService) IndexAll(ctx context.Context, options *GroupsIndexOptions) (groups []Group, err error) {
+	var pageOptions GroupsIndexOptions
+	if options != nil {
+		pageOptions = *options
+	}
+
+	var lastFirstID string
+	for i := 0; i < groupsIndexAllMaxPages; i++ {
+		var page []Group
+		page, err = s.Index(ctx, &pageOptions)
+		if err != nil {
+			return
+		}
+		if len(page) == 0 {
+			return
+		}
+
+		// Stop if the server ignored the page parameter and repeated the
+		// previous page
+		if page[0].ID == lastFirstID {
+			return
+		}
+		lastFirstID = page[0].ID
+
+		groups = append(groups, page...)
+		if pageOptions.Limit != 0 && len(page) < pageOptions.Limit {
+			return
+		}
+		if pageOptions.Page != 0 {
+			pageOptions.Page++
+		} else {
+			pageOptions.Offset++
+		}
+	}
+	err = fmt.Errorf("GroupsService.IndexAll: stopped after %d pages", groupsIndexAllMaxPages)
+	return
+}
+
+// groupsDefaultLimit is the number of groups returned by a groups index request
+// when no limit is set.
+const groupsDefaultLimit = 10
+
+// A GroupsPage is a page of groups returned by GroupsService.IndexPage.
+type GroupsPage struct {
+	Groups []Group
+
+	// HasMore is whether there may be more groups after this page, which is
+	// the case when the page is full.
+	HasMore bool
+
+	options GroupsIndexOptions
+}
+
+// Next returns the options for requesting the next page, or nil if there are no
+// more pages.
+func (p GroupsPage) Next() *GroupsIndexOptions {
+	if !p.HasMore {
+		return nil
+	}
+	next := p.options
+	if next.Page != 0 {
+		next.Page++
+	} else {
+		next.Offset++
+	}
+	return &next
+}
+
+// IndexPage lists a page of the authenticated user's active groups like Index,
+// returning a page that can be used to request the next one:
+//
+//	page, err := groups.IndexPage(ctx, &GroupsIndexOptions{Limit: 20})
+//	for err == nil {
+//		// Use page.Groups
+//		next := page.Next()
+//		if next == nil {
+//			break
+//		}
+//		page, err = groups.IndexPage(ctx, next)
+//	}
+func (s *groupsService) IndexPage(ctx context.Context, options *GroupsIndexOptions) (page GroupsPage, err error) {
+	if options != nil {
+		page.options = *options
+	}
+
+	page.Groups, err = s.Index(ctx, &page.options)
+	if err != nil {
+		return
+	}
+
+	limit := page.options.Limit
+	if limit == 0 {
+		limit = groupsDefaultLimit
+	}
+	page.HasMore = len(page.Groups) >= limit
+	return
+}
+
 // Former list any groups you have left but can rejoin.
-func (s *groupsService) Former() (groups []Group, err error) {
+func (s *groupsService) Former(ctx context.Context) (groups []Group, err error) {
 	var req *http.Request
-	req, err = http.NewRequest(http.MethodGet, BaseURL+"/groups/former", nil)
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+"/groups/former", nil)
 	if err != nil {
 		return
 	}
@@ -191,7 +888,7 @@ func (s *groupsService) Former() (groups []Group, err error) {
 	var respEnv struct {
 		Groups []Group `json:"response"`
 	}
-	err = json.NewDecoder(resp.Body).Decode(&respEnv)
+	err = decodeResponse(resp.Body, &respEnv)
 	if err != nil {
 		return
 	}
@@ -199,14 +896,37 @@ func (s *groupsService) Former() (groups []Group, err error) {
 	return
 }
 
-// Show retrieves a specific group from the given ID.
-func (s *groupsService) Show(id string) (group Group, err error) {
+// A GroupsShowOptions sets all the options for a groups show request.
+type GroupsShowOptions struct {
+	// Omit is a slice of strings sent as a comma-separated string in the request.
+	// Each must be one of the Omit constants, e.g. OmitMemberships to request
+	// the group without its membership list.
+	Omit []string
+}
+
+// Show retrieves a specific group from the given ID. If options is nil the full
+// group is returned.
+func (s *groupsService) Show(ctx context.Context, id string, options *GroupsShowOptions) (group Group, err error) {
+	if options == nil {
+		options = new(GroupsShowOptions)
+	}
+	if err = validateOmit(options.Omit); err != nil {
+		err = fmt.Errorf("GroupsService.Show: %v", err)
+		return
+	}
+
 	var req *http.Request
-	req, err = http.NewRequest(http.MethodGet, BaseURL+fmt.Sprintf("/groups/%s", id), nil)
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+fmt.Sprintf("/groups/%s", id), nil)
 	if err != nil {
 		return
 	}
 
+	if len(options.Omit) > 0 {
+		params := req.URL.Query()
+		params.Set("omit", strings.Join(options.Omit, ","))
+		req.URL.RawQuery = params.Encode()
+	}
+
 	var resp *http.Response
 	resp, err = s.client.Do(req)
 	if err != nil {
@@ -217,7 +937,7 @@ func (s *groupsService) Show(id string) (group Group, err error) {
 	var respEnv struct {
 		Group Group `json:"response"`
 	}
-	err = json.NewDecoder(resp.Body).Decode(&respEnv)
+	err = decodeResponse(resp.Body, &respEnv)
 	if err != nil {
 		return
 	}
@@ -225,30 +945,85 @@ func (s *groupsService) Show(id string) (group Group, err error) {
 	return
 }
 
-// Create creates a new group. See the API documentation for what fields are
-// required.
-func (s *groupsService) Create(g *Group) (group Group, err error) {
-	if g.Name == "" {
-		err = fmt.Errorf("GroupsService.Create: group name is required")
+// groupsConcurrency is the maximum number of concurrent requests made by
+// ShowMany.
+const groupsConcurrency = 4
+
+// ShowMany retrieves the groups with the given IDs, making up to a few requests
+// at a time. The groups retrieved are returned keyed by ID, and if any fail a
+// *BatchError is returned keyed by ID, so one failed group doesn't prevent the
+// others from being returned. If the context is done before all groups have
+// been retrieved the context's error is recorded for the remaining groups.
+func (s *groupsService) ShowMany(ctx context.Context, ids []string) (groups map[string]Group, err error) {
+	groups = make(map[string]Group)
+
+	var mu sync.Mutex
+	err = forEachConcurrently(ctx, "GroupsService.ShowMany", ids, groupsConcurrency, func(id string) error {
+		group, err := s.Show(ctx, id, nil)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		groups[id] = group
+		mu.Unlock()
+		return nil
+	})
+	return
+}
+
+// Members gets the members of a group, e.g. to fill in the members of a group
+// indexed with OmitMemberships. There is no endpoint for only the members, so
+// the full group is requested.
+func (s *groupsService) Members(ctx context.Context, id string) (members []Member, err error) {
+	var group Group
+	group, err = s.Show(ctx, id, nil)
+	if err != nil {
 		return
 	}
-	if len(g.Name) > 140 {
-		err = fmt.Errorf("GroupsService.Create: group name length maximum is 140 characters")
+	members = group.Members
+	return
+}
+
+// A GroupsCreateOptions sets all the options for a groups create request.
+type GroupsCreateOptions struct {
+	// Share is whether to generate a share URL for the group, which anyone can
+	// use to join it.
+	Share bool
+}
+
+// Create creates a new group from the name, description, image URL, and type
+// of the given group. The name is required, and the type must be one of
+// GroupTypePrivate, GroupTypePublic, or empty for the default. If options is
+// nil the group is created without a share URL.
+func (s *groupsService) Create(ctx context.Context, g *Group, options *GroupsCreateOptions) (group Group, err error) {
+	if err = validateGroupCreate(g); err != nil {
+		err = fmt.Errorf("GroupsService.Create: %v", err)
 		return
 	}
-	if len(g.Description) > 255 {
-		err = fmt.Errorf("GroupsService.Create: group description length maximum is 255 characters")
-		return
+
+	var reqEnv struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+		ImageURL    string `json:"image_url,omitempty"`
+		Type        string `json:"type,omitempty"`
+		Share       bool   `json:"share,omitempty"`
+	}
+	reqEnv.Name = g.Name
+	reqEnv.Description = g.Description
+	reqEnv.ImageURL = g.ImageURL
+	reqEnv.Type = g.Type
+	if options != nil {
+		reqEnv.Share = options.Share
 	}
 
 	reqBuf := &bytes.Buffer{}
-	err = json.NewEncoder(reqBuf).Encode(g)
+	err = json.NewEncoder(reqBuf).Encode(&reqEnv)
 	if err != nil {
 		return
 	}
 
 	var req *http.Request
-	req, err = http.NewRequest(http.MethodPost, BaseURL+"/groups", reqBuf)
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+"/groups", reqBuf)
 	if err != nil {
 		return
 	}
@@ -263,7 +1038,7 @@ func (s *groupsService) Create(g *Group) (group Group, err error) {
 	var respEnv struct {
 		Group Group `json:"response"`
 	}
-	err = json.NewDecoder(resp.Body).Decode(&respEnv)
+	err = decodeResponse(resp.Body, &respEnv)
 	if err != nil {
 		return
 	}
@@ -271,14 +1046,52 @@ func (s *groupsService) Create(g *Group) (group Group, err error) {
 	return
 }
 
-// Update updates a group with the given ID.
-func (s *groupsService) Update(id string, g *Group) (group Group, err error) {
-	if g.Name == "" {
-		err = fmt.Errorf("GroupsService.Update: group name is required")
+// CreateWithAvatar uploads an avatar image to the image service, sets the
+// group's image URL to it, and creates the group like Create. The content type
+// must be one supported by ImageService.Upload.
+func (s *groupsService) CreateWithAvatar(ctx context.Context, g *Group, avatar io.Reader, contentType string, options *GroupsCreateOptions) (group Group, err error) {
+	// Validate the group before uploading so an invalid group doesn't leave
+	// an unused image behind
+	if err = validateGroupCreate(g); err != nil {
+		err = fmt.Errorf("GroupsService.CreateWithAvatar: %v", err)
 		return
 	}
-	if len(g.Name) > 140 {
-		err = fmt.Errorf("GroupsService.Update: group name length maximum is 140 characters")
+
+	withAvatar := *g
+	withAvatar.ImageURL, err = NewImageService(s.client).Upload(ctx, avatar, contentType)
+	if err != nil {
+		return
+	}
+	return s.Create(ctx, &withAvatar, options)
+}
+
+// validateGroupCreate checks the fields of a group to be created.
+func validateGroupCreate(g *Group) error {
+	if g.Name == "" {
+		return errors.New("group name is required")
+	}
+	if len(g.Name) > 140 {
+		return errors.New("group name length maximum is 140 characters")
+	}
+	if len(g.Description) > 255 {
+		return errors.New("group description length maximum is 255 characters")
+	}
+	switch g.Type {
+	case "", GroupTypePrivate, GroupTypePublic:
+	default:
+		return errors.New("group type must be 'private' or 'public'")
+	}
+	return nil
+}
+
+// Update updates a group with the given ID.
+func (s *groupsService) Update(ctx context.Context, id string, g *Group) (group Group, err error) {
+	if g.Name == "" {
+		err = fmt.Errorf("GroupsService.Update: group name is required")
+		return
+	}
+	if len(g.Name) > 140 {
+		err = fmt.Errorf("GroupsService.Update: group name length maximum is 140 characters")
 		return
 	}
 	if len(g.Description) > 255 {
@@ -286,14 +1099,23 @@ func (s *groupsService) Update(id string, g *Group) (group Group, err error) {
 		return
 	}
 
+	var reqEnv struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		ImageURL    string `json:"image_url"`
+	}
+	reqEnv.Name = g.Name
+	reqEnv.Description = g.Description
+	reqEnv.ImageURL = g.ImageURL
+
 	reqBuf := &bytes.Buffer{}
-	err = json.NewEncoder(reqBuf).Encode(g)
+	err = json.NewEncoder(reqBuf).Encode(&reqEnv)
 	if err != nil {
 		return
 	}
 
 	var req *http.Request
-	req, err = http.NewRequest(http.MethodPost, BaseURL+fmt.Sprintf("/groups/%s/update", id), reqBuf)
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+fmt.Sprintf("/groups/%s/update", id), reqBuf)
 	if err != nil {
 		return
 	}
@@ -308,7 +1130,7 @@ func (s *groupsService) Update(id string, g *Group) (group Group, err error) {
 	var respEnv struct {
 		Group Group `json:"response"`
 	}
-	err = json.NewDecoder(resp.Body).Decode(&respEnv)
+	err = decodeResponse(resp.Body, &respEnv)
 	if err != nil {
 		return
 	}
@@ -317,9 +1139,9 @@ func (s *groupsService) Update(id string, g *Group) (group Group, err error) {
 }
 
 // Destroy disbands a group. It is only available to the group creator.
-func (s *groupsService) Destroy(id string) (err error) {
+func (s *groupsService) Destroy(ctx context.Context, id string) (err error) {
 	var req *http.Request
-	req, err = http.NewRequest(http.MethodPost, BaseURL+fmt.Sprintf("/groups/%s/destroy", id), nil)
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+fmt.Sprintf("/groups/%s/destroy", id), nil)
 	if err != nil {
 		return
 	}
@@ -335,9 +1157,9 @@ func (s *groupsService) Destroy(id string) (err error) {
 }
 
 // Join joins a shared group.
-func (s *groupsService) Join(id string, shareToken string) (group Group, err error) {
+func (s *groupsService) Join(ctx context.Context, id string, shareToken string) (group Group, err error) {
 	var req *http.Request
-	req, err = http.NewRequest(http.MethodPost, BaseURL+fmt.Sprintf("/groups/%s/join/%s", id, shareToken), nil)
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+fmt.Sprintf("/groups/%s/join/%s", id, shareToken), nil)
 	if err != nil {
 		return
 	}
@@ -354,7 +1176,7 @@ func (s *groupsService) Join(id string, shareToken string) (group Group, err err
 			Group Group `json:"group"`
 		} `json:"response"`
 	}
-	err = json.NewDecoder(resp.Body).Decode(&respEnv)
+	err = decodeResponse(resp.Body, &respEnv)
 	if err != nil {
 		return
 	}
@@ -362,10 +1184,12 @@ func (s *groupsService) Join(id string, shareToken string) (group Group, err err
 	return
 }
 
-// Rejoin rejoins a group. It only works if you previously left the group.
-func (s *groupsService) Rejoin(id string) (group Group, err error) {
+// Rejoin rejoins a group. It only works if you previously left the group, if
+// the request fails and the group is not one of the user's former groups
+// ErrNotFormerGroup is returned.
+func (s *groupsService) Rejoin(ctx context.Context, id string) (group Group, err error) {
 	var req *http.Request
-	req, err = http.NewRequest(http.MethodPost, BaseURL+"/groups/join", nil)
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+"/groups/join", nil)
 	if err != nil {
 		return
 	}
@@ -377,6 +1201,12 @@ func (s *groupsService) Rejoin(id string) (group Group, err error) {
 	var resp *http.Response
 	resp, err = s.client.Do(req)
 	if err != nil {
+		var apiErr Error
+		if errors.As(err, &apiErr) {
+			if former, formerErr := s.IsFormer(ctx, id); formerErr == nil && !former {
+				err = ErrNotFormerGroup
+			}
+		}
 		return
 	}
 	defer resp.Body.Close()
@@ -386,7 +1216,7 @@ func (s *groupsService) Rejoin(id string) (group Group, err error) {
 			Group Group `json:"group"`
 		} `json:"response"`
 	}
-	err = json.NewDecoder(resp.Body).Decode(&respEnv)
+	err = decodeResponse(resp.Body, &respEnv)
 	if err != nil {
 		return
 	}
@@ -394,15 +1224,38 @@ func (s *groupsService) Rejoin(id string) (group Group, err error) {
 	return
 }
 
+// IsFormer reports whether the group with the given ID is one of the user's
+// former groups, which can be rejoined.
+func (s *groupsService) IsFormer(ctx context.Context, id string) (former bool, err error) {
+	var groups []Group
+	groups, err = s.Former(ctx)
+	if err != nil {
+		return
+	}
+	for _, g := range groups {
+		if g.ID == id {
+			former = true
+			return
+		}
+	}
+	return
+}
+
 // MembersService implements all the methods needed to access the members endpoints.
 type MembersService interface {
+	Add(ctx context.Context, groupID string, members []Member) (resultID string, err error)
+	AddResults(ctx context.Context, groupID, resultID string) (result MembersAddResult, err error)
+	AddAndWait(ctx context.Context, groupID string, members []Member) (result MembersAddResult, err error)
+	SetMuted(ctx context.Context, groupID string, muted bool) (member Member, err error)
 	// TODO(jlubawy): implement the following
-	// Add
-	// AddResults
 	// Remove
 	// Update
 }
 
+// ErrResultsNotReady is returned by MembersService.AddResults when the results
+// of an add request are not yet available.
+var ErrResultsNotReady = errors.New("MembersService.AddResults: results are not ready")
+
 type membersService struct {
 	client Client
 }
@@ -413,13 +1266,245 @@ func NewMembersService(client Client) MembersService {
 	}
 }
 
+// Add adds members to a group. Each member must have at least one of a user ID,
+// phone number, or email set, otherwise a *BatchError is returned keyed by the
+// index of each invalid member and no request is made. Members are added
+// asynchronously, the returned result ID can be used to check the results.
+func (s *membersService) Add(ctx context.Context, groupID string, members []Member) (resultID string, err error) {
+	type addMember struct {
+		Nickname    string `json:"nickname,omitempty"`
+		UserID      string `json:"user_id,omitempty"`
+		PhoneNumber string `json:"phone_number,omitempty"`
+		Email       string `json:"email,omitempty"`
+		GUID        string `json:"guid,omitempty"`
+	}
+
+	var reqEnv struct {
+		Members []addMember `json:"members"`
+	}
+	invalid := make(map[string]error)
+	for i, m := range members {
+		if m.UserID == "" && m.PhoneNumber == "" && m.Email == "" {
+			invalid[strconv.Itoa(i)] = errors.New("requires a user ID, phone number, or email")
+			continue
+		}
+		reqEnv.Members = append(reqEnv.Members, addMember{
+			Nickname:    m.Nickname,
+			UserID:      m.UserID,
+			PhoneNumber: m.PhoneNumber,
+			Email:       m.Email,
+			GUID:        m.GUID,
+		})
+	}
+	if len(invalid) > 0 {
+		err = &BatchError{Op: "MembersService.Add", Total: len(members), Errors: invalid}
+		return
+	}
+
+	reqBuf := &bytes.Buffer{}
+	err = json.NewEncoder(reqBuf).Encode(&reqEnv)
+	if err != nil {
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+fmt.Sprintf("/groups/%s/members/add", groupID), reqBuf)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		Response struct {
+			ResultsID string `json:"results_id"`
+		} `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	if respEnv.Response.ResultsID == "" {
+		err = fmt.Errorf("MembersService.Add: response has no results ID")
+		return
+	}
+	resultID = respEnv.Response.ResultsID
+	return
+}
+
+// A MembersAddResult is the result of a members add request.
+type MembersAddResult struct {
+	// Added are the members that were added to the group.
+	Added []Member
+
+	// Failed are the members that were added but then automatically removed,
+	// e.g. because they had previously left the group. The API doesn't say
+	// why a member was removed.
+	Failed []Member
+}
+
+// Missing returns the requested members, identified by their GUIDs, that are in
+// neither Added nor Failed. Members that the server rejected outright, such as
+// those with invalid phone numbers, are left out of the results entirely.
+// Requested members without a GUID are never returned.
+func (r MembersAddResult) Missing(requested []Member) (missing []Member) {
+	seen := make(map[string]bool)
+	for _, m := range r.Added {
+		seen[m.GUID] = true
+	}
+	for _, m := range r.Failed {
+		seen[m.GUID] = true
+	}
+	for _, m := range requested {
+		if m.GUID != "" && !seen[m.GUID] {
+			missing = append(missing, m)
+		}
+	}
+	return
+}
+
+// AddResults gets the results of the add request with the given result ID. If
+// the results are not yet available ErrResultsNotReady is returned and the
+// request should be tried again later.
+func (s *membersService) AddResults(ctx context.Context, groupID, resultID string) (result MembersAddResult, err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+fmt.Sprintf("/groups/%s/members/results/%s", groupID, resultID), nil)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		var apiErr Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusServiceUnavailable {
+			err = ErrResultsNotReady
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		Response struct {
+			Members []json.RawMessage `json:"members"`
+		} `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+
+	for _, raw := range respEnv.Response.Members {
+		var m Member
+		var status struct {
+			Autokicked bool `json:"autokicked"`
+		}
+		if err = json.Unmarshal(raw, &m); err != nil {
+			return
+		}
+		if err = json.Unmarshal(raw, &status); err != nil {
+			return
+		}
+
+		if status.Autokicked {
+			result.Failed = append(result.Failed, m)
+		} else {
+			result.Added = append(result.Added, m)
+		}
+	}
+	return
+}
+
+// AddAndWait adds members to a group like Add and then waits for the results,
+// polling AddResults with an increasing delay until they are ready. If the
+// context is done before then its error is returned.
+func (s *membersService) AddAndWait(ctx context.Context, groupID string, members []Member) (result MembersAddResult, err error) {
+	var resultID string
+	resultID, err = s.Add(ctx, groupID, members)
+	if err != nil {
+		return
+	}
+
+	for attempt := 0; ; attempt++ {
+		result, err = s.AddResults(ctx, groupID, resultID)
+		if err != ErrResultsNotReady {
+			return
+		}
+		if !sleep(ctx, backoff(attempt)) {
+			err = ctx.Err()
+			return
+		}
+	}
+}
+
+// SetMuted mutes or unmutes notifications from a group for the authenticated
+// user, returning the updated membership. An error is returned if the
+// membership returned does not reflect the requested state.
+func (s *membersService) SetMuted(ctx context.Context, groupID string, muted bool) (member Member, err error) {
+	var reqEnv struct {
+		Membership struct {
+			Muted bool `json:"muted"`
+		} `json:"membership"`
+	}
+	reqEnv.Membership.Muted = muted
+
+	reqBuf := &bytes.Buffer{}
+	err = json.NewEncoder(reqBuf).Encode(&reqEnv)
+	if err != nil {
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+fmt.Sprintf("/groups/%s/memberships/update", groupID), reqBuf)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		Member Member `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	member = respEnv.Member
+	if member.Muted != muted {
+		err = fmt.Errorf("MembersService.SetMuted: membership muted is %t after update", member.Muted)
+	}
+	return
+}
+
 // MessagesService implements all the methods needed to access the messages endpoints.
 type MessagesService interface {
-	// TODO(jlubawy): implement the following
-	Index(groupID string, options *MessagesIndexOptions) (messages []Message, err error)
-	// Create
+	Index(ctx context.Context, groupID string, options *MessagesIndexOptions) (messages []Message, err error)
+	IndexWithCount(ctx context.Context, groupID string, options *MessagesIndexOptions) (messages []Message, count int, err error)
+	IndexAll(ctx context.Context, groupID string, pageSize int) (messages []Message, err error)
+	Iterator(ctx context.Context, groupID string, options *MessagesIndexOptions) *MessageIterator
+	Show(ctx context.Context, groupID, messageID string) (message Message, err error)
+	CreateDirect(ctx context.Context, recipientID string, text string, attachments []Attachment) (message DirectMessage, err error)
+	Search(ctx context.Context, groupID, query string, maxMessages int) (messages []Message, err error)
+	Create(ctx context.Context, groupID string, text string, attachments []Attachment) (message Message, err error)
+	CreateWithGUID(ctx context.Context, groupID, sourceGUID string, text string, attachments []Attachment) (message Message, err error)
+	CreateWithImage(ctx context.Context, groupID string, text string, img io.Reader, contentType string) (message Message, err error)
+	Reply(ctx context.Context, groupID, replyToMessageID string, text string) (message Message, err error)
 }
 
+// ErrMessageNotFound is returned by MessagesService.Show when the message could
+// not be found.
+var ErrMessageNotFound = errors.New("MessagesService.Show: message not found")
+
 type messagesService struct {
 	client Client
 }
@@ -444,13 +1529,22 @@ type MessagesIndexOptions struct {
 	Limit int
 }
 
-func (s *messagesService) Index(groupID string, options *MessagesIndexOptions) (messages []Message, err error) {
+// Index lists messages from a group, most recent first.
+func (s *messagesService) Index(ctx context.Context, groupID string, options *MessagesIndexOptions) (messages []Message, err error) {
+	messages, _, err = s.IndexWithCount(ctx, groupID, options)
+	return
+}
+
+// IndexWithCount lists messages from a group like Index, also returning the
+// total number of messages in the group. The count is zero if there are no
+// messages to return.
+func (s *messagesService) IndexWithCount(ctx context.Context, groupID string, options *MessagesIndexOptions) (messages []Message, count int, err error) {
 	if options == nil {
 		options = new(MessagesIndexOptions)
 	}
 
 	var req *http.Request
-	req, err = http.NewRequest(http.MethodGet, BaseURL+fmt.Sprintf("/groups/%s/messages", groupID), nil)
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+fmt.Sprintf("/groups/%s/messages", groupID), nil)
 	if err != nil {
 		return
 	}
@@ -481,90 +1575,261 @@ func (s *messagesService) Index(groupID string, options *MessagesIndexOptions) (
 	}
 	defer resp.Body.Close()
 
+	// There are no messages to return
+	if resp.StatusCode == http.StatusNotModified {
+		return
+	}
+
 	var respEnv struct {
 		Response struct {
 			Count    int       `json:"count"`
 			Messages []Message `json:"messages"`
 		} `json:"response"`
 	}
-	err = json.NewDecoder(resp.Body).Decode(&respEnv)
+	err = decodeResponse(resp.Body, &respEnv)
 	if err != nil {
 		return
 	}
 	messages = respEnv.Response.Messages
+	count = respEnv.Response.Count
 	return
 }
 
-// ChatsService implements all the methods needed to access the chats endpoints.
-type ChatsService interface {
-	// TODO(jlubawy): implement the following
-	// Index
-}
+// IndexAll lists every message of a group, most recent first. Messages are
+// requested pageSize at a time, walking backwards through the group's history
+// until no more messages are returned, or a page contains no new messages. If
+// pageSize is zero the server default is used.
+func (s *messagesService) IndexAll(ctx context.Context, groupID string, pageSize int) (messages []Message, err error) {
+	options := &MessagesIndexOptions{Limit: pageSize}
+	seen := make(map[string]bool)
+	for {
+		var page []Message
+		page, err = s.Index(ctx, groupID, options)
+		if err != nil {
+			return
+		}
+		n := len(messages)
+		messages = appendNewMessages(messages, page, seen)
 
-type chatsService struct {
-	client Client
+		if len(page) == 0 || (pageSize != 0 && len(page) < pageSize) {
+			return
+		}
+
+		// Stop if the page had no new messages or the server ignored the
+		// before ID, otherwise the same page would be requested forever
+		if len(messages) == n || page[len(page)-1].ID == options.BeforeID {
+			return
+		}
+		options.BeforeID = page[len(page)-1].ID
+	}
 }
 
-func NewChatsService(client Client) ChatsService {
-	return &chatsService{
-		client: client,
+// appendNewMessages appends the messages whose IDs haven't been seen before,
+// preserving their order, and marks them as seen. Pages may overlap, e.g. if
+// messages are created or deleted while paginating.
+func appendNewMessages(messages, page []Message, seen map[string]bool) []Message {
+	for _, m := range page {
+		if !seen[m.ID] {
+			seen[m.ID] = true
+			messages = append(messages, m)
+		}
 	}
+	return messages
 }
 
-// DirectMessagesService implements all the methods needed to access the direct
-// messages endpoints.
-type DirectMessagesService interface {
-	// TODO(jlubawy): implement the following
-	// Index
-	// Create
+// Iterator returns an iterator over the messages of a group. If AfterID is set
+// messages are iterated oldest first starting after that message, otherwise
+// they are iterated most recent first starting before BeforeID, or with the
+// most recent message if it isn't set, and stopping at SinceID if it is set.
+// Limit sets the number of messages requested at a time.
+func (s *messagesService) Iterator(ctx context.Context, groupID string, options *MessagesIndexOptions) *MessageIterator {
+	it := &MessageIterator{
+		ctx:     ctx,
+		service: s,
+		groupID: groupID,
+	}
+	if options != nil {
+		it.options = *options
+	}
+	it.sinceID = it.options.SinceID
+	it.options.SinceID = ""
+	return it
 }
 
-type directMessagesService struct {
-	client Client
+// A MessageIterator iterates over the messages of a group, requesting them a
+// page at a time as needed.
+type MessageIterator struct {
+	ctx     context.Context
+	service MessagesService
+	groupID string
+	options MessagesIndexOptions
+	sinceID string
+
+	page    []Message
+	message Message
+	done    bool
+	err     error
 }
 
-func NewDirectMessagesService(client Client) DirectMessagesService {
-	return &directMessagesService{
-		client: client,
+// Next advances the iterator to the next message, which is then available from
+// the Message method. It returns false when there are no more messages or an
+// error occurred.
+func (it *MessageIterator) Next() bool {
+	if it.err != nil {
+		return false
 	}
-}
 
-// LikesService implements all the methods needed to access the likes endpoints.
-type LikesService interface {
-	Create(conversationID, messageID string) (err error)
-	Destroy(conversationID, messageID string) (err error)
-}
+	if len(it.page) == 0 {
+		if it.done {
+			return false
+		}
 
-type likesService struct {
-	client Client
-}
+		var page []Message
+		page, it.err = it.service.Index(it.ctx, it.groupID, &it.options)
+		if it.err != nil {
+			return false
+		}
+		if len(page) == 0 || (it.options.Limit != 0 && len(page) < it.options.Limit) {
+			it.done = true
+		}
+		if len(page) == 0 {
+			return false
+		}
 
-func NewLikesService(client Client) LikesService {
-	return &likesService{
-		client: client,
+		cursor := &it.options.BeforeID
+		if it.options.AfterID != "" {
+			cursor = &it.options.AfterID
+		}
+
+		// Stop if the server ignored the cursor and repeated the previous
+		// page, otherwise the same page would be requested forever
+		if *cursor != "" && page[len(page)-1].ID == *cursor {
+			it.done = true
+			return false
+		}
+		*cursor = page[len(page)-1].ID
+		it.page = page
+	}
+
+	it.message, it.page = it.page[0], it.page[1:]
+	if it.sinceID != "" && compareIDs(it.message.ID, it.sinceID) <= 0 {
+		it.page = nil
+		it.done = true
+		return false
 	}
+	return true
 }
 
-func (s *likesService) Create(conversationID, messageID string) (err error) {
-	var req *http.Request
-	req, err = http.NewRequest(http.MethodPost, BaseURL+fmt.Sprintf("/messages/%s/%s/like", conversationID, messageID), nil)
-	if err != nil {
+// Message returns the current message.
+func (it *MessageIterator) Message() Message { return it.message }
+
+// Err returns the error, if any, that stopped the iteration.
+func (it *MessageIterator) Err() error { return it.err }
+
+// Show gets a single message from a group. There is no endpoint for getting a
+// single message, so instead the message immediately after the ID preceding the
+// given ID is requested. This relies on message IDs being increasing numbers,
+// and ErrMessageNotFound is returned if the message returned has a different
+// ID, e.g. because the message was deleted or belongs to a different group.
+func (s *messagesService) Show(ctx context.Context, groupID, messageID string) (message Message, err error) {
+	n, ok := new(big.Int).SetString(messageID, 10)
+	if !ok || n.Sign() <= 0 {
+		err = fmt.Errorf("MessagesService.Show: message ID must be a positive number")
 		return
 	}
+	afterID := n.Sub(n, big.NewInt(1)).String()
 
-	var resp *http.Response
-	resp, err = s.client.Do(req)
+	var messages []Message
+	messages, err = s.Index(ctx, groupID, &MessagesIndexOptions{
+		AfterID: afterID,
+		Limit:   1,
+	})
 	if err != nil {
 		return
 	}
-	defer resp.Body.Close()
+	if len(messages) == 0 || messages[0].ID != messageID {
+		err = ErrMessageNotFound
+		return
+	}
+	message = messages[0]
+	return
+}
+
+// Search finds the messages of a group whose text contains the query, ignoring
+// case. There is no search endpoint, so messages are requested walking
+// backwards through the group's history until maxMessages have been scanned or
+// there are no more messages. If maxMessages is zero the entire history is
+// scanned. Matching messages are returned oldest first.
+func (s *messagesService) Search(ctx context.Context, groupID, query string, maxMessages int) (messages []Message, err error) {
+	query = strings.ToLower(query)
+
+	seen := make(map[string]bool)
+	it := s.Iterator(ctx, groupID, &MessagesIndexOptions{Limit: 100})
+	for scanned := 0; (maxMessages == 0 || scanned < maxMessages) && it.Next(); scanned++ {
+		if m := it.Message(); strings.Contains(strings.ToLower(m.Text), query) {
+			messages = appendNewMessages(messages, []Message{m}, seen)
+		}
+	}
+	if err = it.Err(); err != nil {
+		return
+	}
 
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
 	return
 }
 
-func (s *likesService) Destroy(conversationID, messageID string) (err error) {
+// compareIDs compares two numeric IDs, returning -1, 0, or 1 if a is less than,
+// equal to, or greater than b.
+func compareIDs(a, b string) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// Create posts a new message to a group. A source GUID is generated for the
+// message automatically. Either text or attachments must be provided, and any
+// attachments must be valid, see Attachment.Validate.
+func (s *messagesService) Create(ctx context.Context, groupID string, text string, attachments []Attachment) (message Message, err error) {
+	return s.CreateWithGUID(ctx, groupID, "", text, attachments)
+}
+
+// CreateWithGUID posts a new message to a group like Create using the given
+// source GUID, so that retrying a failed request doesn't post the message
+// twice, see NewSourceGUID. If the source GUID is empty one is generated.
+func (s *messagesService) CreateWithGUID(ctx context.Context, groupID, sourceGUID string, text string, attachments []Attachment) (message Message, err error) {
+	if err = validateMessage(text, attachments); err != nil {
+		err = fmt.Errorf("MessagesService.Create: %v", err)
+		return
+	}
+	if sourceGUID == "" {
+		sourceGUID = NewSourceGUID()
+	}
+
+	var reqEnv struct {
+		Message struct {
+			SourceGUID  string       `json:"source_guid"`
+			Text        string       `json:"text"`
+			Attachments []Attachment `json:"attachments,omitempty"`
+		} `json:"message"`
+	}
+	reqEnv.Message.SourceGUID = sourceGUID
+	reqEnv.Message.Text = text
+	reqEnv.Message.Attachments = attachments
+
+	reqBuf := &bytes.Buffer{}
+	err = json.NewEncoder(reqBuf).Encode(&reqEnv)
+	if err != nil {
+		return
+	}
+
 	var req *http.Request
-	req, err = http.NewRequest(http.MethodPost, BaseURL+fmt.Sprintf("/messages/%s/%s/unlike", conversationID, messageID), nil)
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+fmt.Sprintf("/groups/%s/messages", groupID), reqBuf)
 	if err != nil {
 		return
 	}
@@ -576,52 +1841,909 @@ func (s *likesService) Destroy(conversationID, messageID string) (err error) {
 	}
 	defer resp.Body.Close()
 
+	var respEnv struct {
+		Response struct {
+			Message Message `json:"message"`
+		} `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	message = respEnv.Response.Message
 	return
 }
 
-// LeaderboardService implements all the methods needed to access the leaderboard
-// endpoints.
-type LeaderboardService interface {
-	// TODO(jlubawy): implement the following
-	// Index
-	// MyLikes
-	// MyHits
-}
+// CreateWithImage uploads an image to the image service and posts a new message
+// to a group with the image attached. The text may be empty. The content type
+// must be one supported by ImageService.Upload.
+func (s *messagesService) CreateWithImage(ctx context.Context, groupID string, text string, img io.Reader, contentType string) (message Message, err error) {
+	// Validate the text before uploading so an invalid message doesn't leave
+	// an unused image behind
+	if text != "" {
+		if err = validateMessage(text, nil); err != nil {
+			err = fmt.Errorf("MessagesService.CreateWithImage: %v", err)
+			return
+		}
+	}
+
+	var imageURL string
+	imageURL, err = NewImageService(s.client).Upload(ctx, img, contentType)
+	if err != nil {
+		return
+	}
+
+	return s.Create(ctx, groupID, text, []Attachment{NewImageAttachment(imageURL)})
+}
+
+// Reply posts a new message to a group replying to the message with the given
+// ID. The message being replied to is retrieved first so that, if it is itself
+// a reply, the reply is added to the same reply chain.
+func (s *messagesService) Reply(ctx context.Context, groupID, replyToMessageID string, text string) (message Message, err error) {
+	var target Message
+	target, err = s.Show(ctx, groupID, replyToMessageID)
+	if err != nil {
+		return
+	}
+
+	baseReplyID := target.ID
+	for _, a := range target.Attachments {
+		if a.IsTypeReply() && a.BaseReplyID != "" {
+			baseReplyID = a.BaseReplyID
+			break
+		}
+	}
+
+	return s.Create(ctx, groupID, text, []Attachment{NewReplyAttachment(target.ID, baseReplyID)})
+}
+
+// CreateDirect posts a new direct message to the user with the given ID. A
+// source GUID is generated for the message automatically. Either text or
+// attachments must be provided, and any attachments must be valid, see
+// Attachment.Validate.
+func (s *messagesService) CreateDirect(ctx context.Context, recipientID string, text string, attachments []Attachment) (message DirectMessage, err error) {
+	if err = validateMessage(text, attachments); err != nil {
+		err = fmt.Errorf("MessagesService.CreateDirect: %v", err)
+		return
+	}
+
+	var reqEnv struct {
+		DirectMessage struct {
+			SourceGUID  string       `json:"source_guid"`
+			RecipientID string       `json:"recipient_id"`
+			Text        string       `json:"text"`
+			Attachments []Attachment `json:"attachments,omitempty"`
+		} `json:"direct_message"`
+	}
+	reqEnv.DirectMessage.SourceGUID = NewSourceGUID()
+	reqEnv.DirectMessage.RecipientID = recipientID
+	reqEnv.DirectMessage.Text = text
+	reqEnv.DirectMessage.Attachments = attachments
+
+	reqBuf := &bytes.Buffer{}
+	err = json.NewEncoder(reqBuf).Encode(&reqEnv)
+	if err != nil {
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+"/direct_messages", reqBuf)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		Response struct {
+			DirectMessage DirectMessage `json:"direct_message"`
+		} `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	message = respEnv.Response.DirectMessage
+	return
+}
+
+// validateMessage checks the text and attachments of a message to be created.
+func validateMessage(text string, attachments []Attachment) error {
+	if text == "" && len(attachments) == 0 {
+		return errors.New("message text or attachments are required")
+	}
+	if utf8.RuneCountInString(text) > 1000 {
+		return errors.New("message text length maximum is 1000 characters")
+	}
+
+	var attachmentErrs []string
+	for i, a := range attachments {
+		if err := a.Validate(); err != nil {
+			attachmentErrs = append(attachmentErrs, fmt.Sprintf("attachment %d: %v", i, err))
+		}
+	}
+	if len(attachmentErrs) > 0 {
+		return errors.New(strings.Join(attachmentErrs, "; "))
+	}
+	return nil
+}
+
+// NewSourceGUID returns a random (version 4) UUID for use as a message source
+// GUID. It panics if random bytes can't be read from the system.
+//
+// GroupMe uses the source GUID of a message to detect duplicates: a message
+// created with the same source GUID as an earlier message in the same
+// conversation is not posted again. A random source GUID makes every message
+// unique, use SourceGUIDFrom instead if a failed request may be retried.
+func NewSourceGUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("NewSourceGUID: %v", err))
+	}
+	return formatUUID(b, 4)
+}
+
+// sourceGUIDNamespace is the namespace of the UUIDs returned by SourceGUIDFrom.
+var sourceGUIDNamespace = []byte("github.com/jlubawy/go-groupme")
+
+// SourceGUIDFrom returns a name-based (version 5) UUID derived from the given
+// seed for use as a message source GUID. The same seed always returns the same
+// GUID, so retrying a request with it can't post the message twice. The seed
+// should identify the logical message, e.g. by including the conversation ID
+// and a timestamp or sequence number along with the text.
+func SourceGUIDFrom(seed string) string {
+	h := sha1.New()
+	h.Write(sourceGUIDNamespace)
+	h.Write([]byte(seed))
+
+	var b [16]byte
+	copy(b[:], h.Sum(nil))
+	return formatUUID(b, 5)
+}
+
+// formatUUID sets the version and variant bits of the UUID and formats it.
+func formatUUID(b [16]byte, version byte) string {
+	b[6] = (b[6] & 0x0f) | version<<4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// ChatsService implements all the methods needed to access the chats endpoints.
+type ChatsService interface {
+	Index(ctx context.Context, options *ChatsIndexOptions) (chats []Chat, err error)
+}
+
+type chatsService struct {
+	client Client
+}
+
+func NewChatsService(client Client) ChatsService {
+	return &chatsService{
+		client: client,
+	}
+}
+
+// A ChatsIndexOptions sets all the options for a chats index request.
+type ChatsIndexOptions struct {
+	// Offset is the page offset to start the index request at. It starts at zero
+	// unlike the 'page' parameter. If set to zero no parameter is sent in the
+	// request and the server default value is used.
+	Offset int
+
+	// Limit limits the number of chats returned by the index request. If set
+	// to zero no parameter is sent in the request and the server default value
+	// is used.
+	Limit int
+}
+
+// Index lists the authenticated user's direct message chats.
+func (s *chatsService) Index(ctx context.Context, options *ChatsIndexOptions) (chats []Chat, err error) {
+	if options == nil {
+		options = new(ChatsIndexOptions)
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+"/chats", nil)
+	if err != nil {
+		return
+	}
+
+	params := req.URL.Query()
+	if options.Offset != 0 {
+		params.Set("page", strconv.Itoa(options.Offset+1))
+	}
+	if options.Limit != 0 {
+		params.Set("per_page", strconv.Itoa(options.Limit))
+	}
+	req.URL.RawQuery = params.Encode()
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		Chats []Chat `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	chats = respEnv.Chats
+	return
+}
+
+// DirectMessagesService implements all the methods needed to access the direct
+// messages endpoints.
+type DirectMessagesService interface {
+	Index(ctx context.Context, otherUserID string, options *DirectMessagesIndexOptions) (messages []DirectMessage, err error)
+	Iterator(ctx context.Context, otherUserID string, options *DirectMessagesIndexOptions) *DirectMessageIterator
+	Create(ctx context.Context, otherUserID string, text string, attachments []Attachment) (message DirectMessage, err error)
+}
+
+type directMessagesService struct {
+	client Client
+}
+
+func NewDirectMessagesService(client Client) DirectMessagesService {
+	return &directMessagesService{
+		client: client,
+	}
+}
+
+// A DirectMessagesIndexOptions sets all the options for a direct messages index
+// request.
+type DirectMessagesIndexOptions struct {
+	// Returns messages created before the given message ID.
+	BeforeID string
+
+	// Returns most recent messages created after the given message ID
+	SinceID string
+}
+
+// Index lists the direct messages between the authenticated user and another
+// user, most recent first. Messages are returned 20 at a time.
+func (s *directMessagesService) Index(ctx context.Context, otherUserID string, options *DirectMessagesIndexOptions) (messages []DirectMessage, err error) {
+	if options == nil {
+		options = new(DirectMessagesIndexOptions)
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+"/direct_messages", nil)
+	if err != nil {
+		return
+	}
+
+	params := req.URL.Query()
+	params.Set("other_user_id", otherUserID)
+	if options.BeforeID != "" {
+		params.Set("before_id", options.BeforeID)
+	}
+	if options.SinceID != "" {
+		params.Set("since_id", options.SinceID)
+	}
+	req.URL.RawQuery = params.Encode()
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	// There are no messages to return
+	if resp.StatusCode == http.StatusNotModified {
+		return
+	}
+
+	var respEnv struct {
+		Response struct {
+			Count          int             `json:"count"`
+			DirectMessages []DirectMessage `json:"direct_messages"`
+		} `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	messages = respEnv.Response.DirectMessages
+	return
+}
+
+// Create posts a new direct message to the other user, see
+// MessagesService.CreateDirect.
+func (s *directMessagesService) Create(ctx context.Context, otherUserID string, text string, attachments []Attachment) (message DirectMessage, err error) {
+	return NewMessagesService(s.client).CreateDirect(ctx, otherUserID, text, attachments)
+}
+
+// Iterator returns an iterator over the direct messages between the
+// authenticated user and another user. Messages are iterated most recent first
+// starting before BeforeID, or with the most recent message if it isn't set,
+// and stopping at SinceID if it is set.
+func (s *directMessagesService) Iterator(ctx context.Context, otherUserID string, options *DirectMessagesIndexOptions) *DirectMessageIterator {
+	it := &DirectMessageIterator{
+		ctx:         ctx,
+		service:     s,
+		otherUserID: otherUserID,
+	}
+	if options != nil {
+		it.options = *options
+	}
+	it.sinceID = it.options.SinceID
+	it.options.SinceID = ""
+	return it
+}
+
+// A DirectMessageIterator iterates over the direct messages with another user,
+// requesting them a page at a time as needed.
+type DirectMessageIterator struct {
+	ctx         context.Context
+	service     DirectMessagesService
+	otherUserID string
+	options     DirectMessagesIndexOptions
+	sinceID     string
+
+	page    []DirectMessage
+	message DirectMessage
+	done    bool
+	err     error
+}
+
+// Next advances the iterator to the next message, which is then available from
+// the Message method. It returns false when there are no more messages or an
+// error occurred.
+func (it *DirectMessageIterator) Next() bool {
+	if it.err != nil || it.done {
+		return false
+	}
+
+	if len(it.page) == 0 {
+		var page []DirectMessage
+		page, it.err = it.service.Index(it.ctx, it.otherUserID, &it.options)
+		if it.err != nil {
+			return false
+		}
+		if len(page) == 0 {
+			it.done = true
+			return false
+		}
+
+		// Stop if the server ignored the cursor and repeated the previous
+		// page, otherwise the same page would be requested forever
+		if it.options.BeforeID != "" && page[len(page)-1].ID == it.options.BeforeID {
+			it.done = true
+			return false
+		}
+		it.options.BeforeID = page[len(page)-1].ID
+		it.page = page
+	}
+
+	it.message, it.page = it.page[0], it.page[1:]
+	if it.sinceID != "" && compareIDs(it.message.ID, it.sinceID) <= 0 {
+		it.page = nil
+		it.done = true
+		return false
+	}
+	return true
+}
+
+// Message returns the current message.
+func (it *DirectMessageIterator) Message() DirectMessage { return it.message }
+
+// Err returns the error, if any, that stopped the iteration.
+func (it *DirectMessageIterator) Err() error { return it.err }
+
+// LikesService implements all the methods needed to access the likes endpoints.
+type LikesService interface {
+	Create(ctx context.Context, conversationID, messageID string) (err error)
+	Destroy(ctx context.Context, conversationID, messageID string) (err error)
+	LikeAll(ctx context.Context, conversationID string, messageIDs []string) (err error)
+	UnlikeAll(ctx context.Context, conversationID string, messageIDs []string) (err error)
+	Toggle(ctx context.Context, conversationID, messageID string, currentlyLiked bool) (nowLiked bool, err error)
+}
+
+type likesService struct {
+	client Client
+}
+
+func NewLikesService(client Client) LikesService {
+	return &likesService{
+		client: client,
+	}
+}
+
+func (s *likesService) Create(ctx context.Context, conversationID, messageID string) (err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+fmt.Sprintf("/messages/%s/%s/like", conversationID, messageID), nil)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	return
+}
+
+func (s *likesService) Destroy(ctx context.Context, conversationID, messageID string) (err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+fmt.Sprintf("/messages/%s/%s/unlike", conversationID, messageID), nil)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	return
+}
+
+// Toggle likes the message if it isn't currently liked, otherwise it unlikes
+// it, returning whether the message is now liked. If the request fails the
+// current state is returned with the error.
+func (s *likesService) Toggle(ctx context.Context, conversationID, messageID string, currentlyLiked bool) (nowLiked bool, err error) {
+	if currentlyLiked {
+		err = s.Destroy(ctx, conversationID, messageID)
+	} else {
+		err = s.Create(ctx, conversationID, messageID)
+	}
+	if err != nil {
+		return currentlyLiked, err
+	}
+	return !currentlyLiked, nil
+}
+
+// likesConcurrency is the maximum number of concurrent requests made by the
+// batch likes methods.
+const likesConcurrency = 4
+
+// LikeAll likes each of the messages, making up to a few requests at a time. If
+// any fail a *BatchError is returned keyed by message ID. If the context is
+// done before all messages have been liked the remaining messages are skipped
+// and the context's error is recorded for them.
+func (s *likesService) LikeAll(ctx context.Context, conversationID string, messageIDs []string) (err error) {
+	return forEachConcurrently(ctx, "LikesService.LikeAll", messageIDs, likesConcurrency, func(messageID string) error {
+		return s.Create(ctx, conversationID, messageID)
+	})
+}
+
+// UnlikeAll unlikes each of the messages like LikeAll.
+func (s *likesService) UnlikeAll(ctx context.Context, conversationID string, messageIDs []string) (err error) {
+	return forEachConcurrently(ctx, "LikesService.UnlikeAll", messageIDs, likesConcurrency, func(messageID string) error {
+		return s.Destroy(ctx, conversationID, messageID)
+	})
+}
+
+// forEachConcurrently calls fn for each ID with at most limit calls running at
+// once, returning a *BatchError for op if any fail. No further calls are made
+// once the context is done, and the context's error is recorded for the IDs
+// that were skipped.
+func forEachConcurrently(ctx context.Context, op string, ids []string, limit int, fn func(id string) error) (err error) {
+	errs := make(map[string]error)
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, limit)
+	)
+	for i, id := range ids {
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if ctx.Err() != nil {
+			mu.Lock()
+			for _, id := range ids[i:] {
+				errs[id] = ctx.Err()
+			}
+			mu.Unlock()
+			break
+		}
+
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := fn(id); err != nil {
+				mu.Lock()
+				errs[id] = err
+				mu.Unlock()
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		err = &BatchError{Op: op, Total: len(ids), Errors: errs}
+	}
+	return
+}
+
+// ReactionsService implements all the methods needed to react to messages with
+// emoji. Reactions are made using the likes endpoints with a like icon, so a
+// reaction replaces any like or other reaction by the user.
+type ReactionsService interface {
+	Create(ctx context.Context, conversationID, messageID, reactionCode string) (err error)
+	Destroy(ctx context.Context, conversationID, messageID, reactionCode string) (err error)
+}
+
+type reactionsService struct {
+	client Client
+}
+
+func NewReactionsService(client Client) ReactionsService {
+	return &reactionsService{
+		client: client,
+	}
+}
+
+// Create reacts to a message with the emoji with the given code, e.g. "😂".
+func (s *reactionsService) Create(ctx context.Context, conversationID, messageID, reactionCode string) (err error) {
+	return s.react(ctx, "ReactionsService.Create", "like", conversationID, messageID, reactionCode)
+}
+
+// Destroy removes a reaction made with Create.
+func (s *reactionsService) Destroy(ctx context.Context, conversationID, messageID, reactionCode string) (err error) {
+	return s.react(ctx, "ReactionsService.Destroy", "unlike", conversationID, messageID, reactionCode)
+}
+
+// react makes a likes request with the given action for the reaction, using
+// method as the prefix of any error.
+func (s *reactionsService) react(ctx context.Context, method, action, conversationID, messageID, reactionCode string) (err error) {
+	if reactionCode == "" {
+		err = fmt.Errorf("%s: reaction code is required", method)
+		return
+	}
+
+	var reqEnv struct {
+		LikeIcon struct {
+			Type string `json:"type"`
+			Code string `json:"code"`
+		} `json:"like_icon"`
+	}
+	reqEnv.LikeIcon.Type = "unicode"
+	reqEnv.LikeIcon.Code = reactionCode
+
+	reqBuf := &bytes.Buffer{}
+	err = json.NewEncoder(reqBuf).Encode(&reqEnv)
+	if err != nil {
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+fmt.Sprintf("/messages/%s/%s/%s", conversationID, messageID, action), reqBuf)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	return
+}
+
+// LeaderboardService implements all the methods needed to access the leaderboard
+// endpoints.
+type LeaderboardService interface {
+	Index(ctx context.Context, groupID string, period string) (messages []Message, err error)
+	MyLikes(ctx context.Context, groupID string) (messages []Message, err error)
+	MyHits(ctx context.Context, groupID string) (messages []Message, err error)
+}
 
 type leaderboardService struct {
 	client Client
 }
 
-func NewLeaderboardService(client Client) LeaderboardService {
-	return &leaderboardService{
-		client: client,
+func NewLeaderboardService(client Client) LeaderboardService {
+	return &leaderboardService{
+		client: client,
+	}
+}
+
+// Periods accepted by LeaderboardService.Index.
+const (
+	LeaderboardPeriodDay   = "day"
+	LeaderboardPeriodWeek  = "week"
+	LeaderboardPeriodMonth = "month"
+)
+
+// Index lists the most liked messages of a group for the given period, which
+// must be one of the LeaderboardPeriod constants. Messages are ordered by the
+// number of likes they have received.
+func (s *leaderboardService) Index(ctx context.Context, groupID string, period string) (messages []Message, err error) {
+	switch period {
+	case LeaderboardPeriodDay, LeaderboardPeriodWeek, LeaderboardPeriodMonth:
+	default:
+		err = fmt.Errorf("LeaderboardService.Index: invalid period '%s', must be one of '%s', '%s', or '%s'",
+			period, LeaderboardPeriodDay, LeaderboardPeriodWeek, LeaderboardPeriodMonth)
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+fmt.Sprintf("/groups/%s/likes", groupID), nil)
+	if err != nil {
+		return
+	}
+
+	params := req.URL.Query()
+	params.Set("period", period)
+	req.URL.RawQuery = params.Encode()
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		Response struct {
+			Messages []Message `json:"messages"`
+		} `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	messages = respEnv.Response.Messages
+	return
+}
+
+// MyLikes lists the messages of a group that the authenticated user has liked.
+func (s *leaderboardService) MyLikes(ctx context.Context, groupID string) (messages []Message, err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+fmt.Sprintf("/groups/%s/likes/mine", groupID), nil)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		Response struct {
+			Messages []Message `json:"messages"`
+		} `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	messages = respEnv.Response.Messages
+	return
+}
+
+// MyHits lists the authenticated user's messages in a group that have been
+// liked by others.
+func (s *leaderboardService) MyHits(ctx context.Context, groupID string) (messages []Message, err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+fmt.Sprintf("/groups/%s/likes/for_me", groupID), nil)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		Response struct {
+			Messages []Message `json:"messages"`
+		} `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	messages = respEnv.Response.Messages
+	return
+}
+
+// BotsService implements all the methods needed to access the bots endpoints.
+type BotsService interface {
+	Create(ctx context.Context, bot *Bot) (b Bot, err error)
+	PostMessage(ctx context.Context, botID string, text string, attachments []Attachment) (err error)
+	Index(ctx context.Context) (bots []Bot, err error)
+	Destroy(ctx context.Context, botID string) (err error)
+}
+
+type botsService struct {
+	client Client
+}
+
+func NewBotsService(client Client) BotsService {
+	return &botsService{
+		client: client,
+	}
+}
+
+// Create creates a new bot. The bot name and group ID are required.
+func (s *botsService) Create(ctx context.Context, bot *Bot) (b Bot, err error) {
+	if bot.Name == "" {
+		err = fmt.Errorf("BotsService.Create: bot name is required")
+		return
+	}
+	if bot.GroupID == "" {
+		err = fmt.Errorf("BotsService.Create: bot group ID is required")
+		return
+	}
+
+	var reqEnv struct {
+		Bot struct {
+			Name           string `json:"name"`
+			GroupID        string `json:"group_id"`
+			AvatarURL      string `json:"avatar_url,omitempty"`
+			CallbackURL    string `json:"callback_url,omitempty"`
+			DmNotification bool   `json:"dm_notification,omitempty"`
+		} `json:"bot"`
+	}
+	reqEnv.Bot.Name = bot.Name
+	reqEnv.Bot.GroupID = bot.GroupID
+	reqEnv.Bot.AvatarURL = bot.AvatarURL
+	reqEnv.Bot.CallbackURL = bot.CallbackURL
+	reqEnv.Bot.DmNotification = bot.DmNotification
+
+	reqBuf := &bytes.Buffer{}
+	err = json.NewEncoder(reqBuf).Encode(&reqEnv)
+	if err != nil {
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+"/bots", reqBuf)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		Response struct {
+			Bot Bot `json:"bot"`
+		} `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	b = respEnv.Response.Bot
+	return
+}
+
+// PostMessage posts a message as a bot to the bot's group. Either text or
+// attachments must be provided, and any attachments must be valid, see
+// Attachment.Validate.
+func (s *botsService) PostMessage(ctx context.Context, botID string, text string, attachments []Attachment) (err error) {
+	if err = validateMessage(text, attachments); err != nil {
+		err = fmt.Errorf("BotsService.PostMessage: %v", err)
+		return
+	}
+
+	var reqEnv struct {
+		BotID       string       `json:"bot_id"`
+		Text        string       `json:"text"`
+		Attachments []Attachment `json:"attachments,omitempty"`
+	}
+	reqEnv.BotID = botID
+	reqEnv.Text = text
+	reqEnv.Attachments = attachments
+
+	reqBuf := &bytes.Buffer{}
+	err = json.NewEncoder(reqBuf).Encode(&reqEnv)
+	if err != nil {
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+"/bots/post", reqBuf)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	return
+}
+
+// Index lists the bots the authenticated user has created.
+func (s *botsService) Index(ctx context.Context) (bots []Bot, err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+"/bots", nil)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
 	}
-}
+	defer resp.Body.Close()
 
-// BotsService implements all the methods needed to access the bots endpoints.
-type BotsService interface {
-	// TODO(jlubawy): implement the following
-	// Create
-	// PostMessage
-	// Index
-	// Destroy
+	var respEnv struct {
+		Bots []Bot `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	bots = respEnv.Bots
+	return
 }
 
-type botsService struct {
-	client Client
-}
+// Destroy removes a bot.
+func (s *botsService) Destroy(ctx context.Context, botID string) (err error) {
+	var reqEnv struct {
+		BotID string `json:"bot_id"`
+	}
+	reqEnv.BotID = botID
 
-func NewBotsService(client Client) BotsService {
-	return &botsService{
-		client: client,
+	reqBuf := &bytes.Buffer{}
+	err = json.NewEncoder(reqBuf).Encode(&reqEnv)
+	if err != nil {
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+"/bots/destroy", reqBuf)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
 	}
+	defer resp.Body.Close()
+
+	return
 }
 
 // UsersService implements all the methods needed to access the users endpoints.
 type UsersService interface {
-	// TODO(jlubawy): implement the following
-	// Me
-	// Update
+	Me(ctx context.Context) (user User, err error)
+	Verify(ctx context.Context) (err error)
+	Update(ctx context.Context, u *UserUpdate) (user User, err error)
 }
 
 type usersService struct {
@@ -634,11 +2756,92 @@ func NewUsersService(client Client) UsersService {
 	}
 }
 
+// Me gets the authenticated user's details.
+func (s *usersService) Me(ctx context.Context) (user User, err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+"/users/me", nil)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		User User `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	user = respEnv.User
+	return
+}
+
+// Verify checks that the client's access token is valid by getting the
+// authenticated user's details. ErrUnauthorized is returned if the token is
+// rejected, otherwise any error making the request is returned.
+func (s *usersService) Verify(ctx context.Context) (err error) {
+	_, err = s.Me(ctx)
+	if errors.Is(err, ErrUnauthorized) {
+		err = ErrUnauthorized
+	}
+	return
+}
+
+// A UserUpdate sets the user attributes to update. Only non-empty fields are
+// sent in the request, all others are left unchanged.
+type UserUpdate struct {
+	AvatarURL string `json:"avatar_url,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Email     string `json:"email,omitempty"`
+	ZipCode   string `json:"zip_code,omitempty"`
+}
+
+// Update updates the authenticated user's details.
+func (s *usersService) Update(ctx context.Context, u *UserUpdate) (user User, err error) {
+	if u == nil {
+		u = new(UserUpdate)
+	}
+
+	reqBuf := &bytes.Buffer{}
+	err = json.NewEncoder(reqBuf).Encode(u)
+	if err != nil {
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+"/users/update", reqBuf)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		User User `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	user = respEnv.User
+	return
+}
+
 // SmsService implements all the methods needed to access the SMS endpoints.
 type SmsService interface {
-	// TODO(jlubawy): implement the following
-	// Create
-	// Delete
+	Create(ctx context.Context, duration int, registrationID string) (err error)
+	Delete(ctx context.Context) (err error)
 }
 
 type smsService struct {
@@ -651,13 +2854,69 @@ func NewSmsService(client Client) SmsService {
 	}
 }
 
+// Create enables SMS mode for the given duration in hours, which must be between
+// 1 and 48. If a push notification client registration ID is given, push
+// notifications for that client are suppressed while SMS mode is enabled.
+func (s *smsService) Create(ctx context.Context, duration int, registrationID string) (err error) {
+	if duration < 1 || duration > 48 {
+		err = fmt.Errorf("SmsService.Create: duration must be between 1 and 48 hours")
+		return
+	}
+
+	var reqEnv struct {
+		Duration       int    `json:"duration"`
+		RegistrationID string `json:"registration_id,omitempty"`
+	}
+	reqEnv.Duration = duration
+	reqEnv.RegistrationID = registrationID
+
+	reqBuf := &bytes.Buffer{}
+	err = json.NewEncoder(reqBuf).Encode(&reqEnv)
+	if err != nil {
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+"/users/sms_mode", reqBuf)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	return
+}
+
+// Delete disables SMS mode.
+func (s *smsService) Delete(ctx context.Context) (err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+"/users/sms_mode/delete", nil)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	return
+}
+
 // BlocksService implements all the methods needed to access the blocks endpoints.
 type BlocksService interface {
+	BlockBetween(ctx context.Context, userID, otherUserID string) (between bool, err error)
+	CreateBlock(ctx context.Context, userID, otherUserID string) (block Block, err error)
+	Unblock(ctx context.Context, userID, otherUserID string) (err error)
 	// TODO(jlubawy): implement the following
 	// Index
-	// BlockBetween
-	// CreateBlock
-	// Unblock
 }
 
 type blocksService struct {
@@ -669,3 +2928,187 @@ func NewBlocksService(client Client) BlocksService {
 		client: client,
 	}
 }
+
+// BlockBetween asks if a block exists between two users.
+func (s *blocksService) BlockBetween(ctx context.Context, userID, otherUserID string) (between bool, err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, baseURL(s.client)+"/blocks/between", nil)
+	if err != nil {
+		return
+	}
+
+	params := req.URL.Query()
+	params.Set("user", userID)
+	params.Set("otherUser", otherUserID)
+	req.URL.RawQuery = params.Encode()
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		Response struct {
+			Between bool `json:"between"`
+		} `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	between = respEnv.Response.Between
+	return
+}
+
+// CreateBlock creates a block between two users.
+func (s *blocksService) CreateBlock(ctx context.Context, userID, otherUserID string) (block Block, err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, baseURL(s.client)+"/blocks", nil)
+	if err != nil {
+		return
+	}
+
+	params := req.URL.Query()
+	params.Set("user", userID)
+	params.Set("otherUser", otherUserID)
+	req.URL.RawQuery = params.Encode()
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var respEnv struct {
+		Response struct {
+			Block Block `json:"block"`
+		} `json:"response"`
+	}
+	err = decodeResponse(resp.Body, &respEnv)
+	if err != nil {
+		return
+	}
+	block = respEnv.Response.Block
+	return
+}
+
+// Unblock removes a block between two users. Unlike most endpoints the users
+// are sent as URL parameters of a DELETE request.
+func (s *blocksService) Unblock(ctx context.Context, userID, otherUserID string) (err error) {
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodDelete, baseURL(s.client)+"/blocks", nil)
+	if err != nil {
+		return
+	}
+
+	params := req.URL.Query()
+	params.Set("user", userID)
+	params.Set("otherUser", otherUserID)
+	req.URL.RawQuery = params.Encode()
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	return
+}
+
+// ImageService implements all the methods needed to access the image service.
+type ImageService interface {
+	Upload(ctx context.Context, r io.Reader, contentType string) (imageURL string, err error)
+	UploadWithProgress(ctx context.Context, r io.Reader, size int64, contentType string, progress func(bytesSent int64)) (imageURL string, err error)
+}
+
+type imageService struct {
+	client Client
+}
+
+func NewImageService(client Client) ImageService {
+	return &imageService{
+		client: client,
+	}
+}
+
+// Upload uploads an image to the image service and returns its URL, which can
+// then be used in image attachments and avatars. The content type must be one
+// of "image/jpeg", "image/png", or "image/gif".
+func (s *imageService) Upload(ctx context.Context, r io.Reader, contentType string) (imageURL string, err error) {
+	return s.UploadWithProgress(ctx, r, 0, contentType, nil)
+}
+
+// UploadWithProgress uploads an image like Upload, calling progress with the
+// total number of bytes sent so far as the image is read. The size of the
+// image is sent as the request's content length if it is greater than zero,
+// otherwise the image is sent without a length. Progress may be nil.
+func (s *imageService) UploadWithProgress(ctx context.Context, r io.Reader, size int64, contentType string, progress func(bytesSent int64)) (imageURL string, err error) {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		err = fmt.Errorf("ImageService.Upload: unsupported content type '%s'", contentType)
+		return
+	}
+
+	body := r
+	if progress != nil {
+		body = &progressReader{r: r, progress: progress}
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, imageServiceURL(s.client)+"/pictures", body)
+	if err != nil {
+		return
+	}
+	if size > 0 {
+		req.ContentLength = size
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	// The image service uses a different envelope to the API
+	var respEnv struct {
+		Payload struct {
+			URL string `json:"url"`
+		} `json:"payload"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&respEnv)
+	if err == io.EOF {
+		err = ErrEmptyResponse
+	}
+	if err != nil {
+		return
+	}
+	if respEnv.Payload.URL == "" {
+		err = fmt.Errorf("ImageService.Upload: response has no payload url")
+		return
+	}
+	imageURL = respEnv.Payload.URL
+	return
+}
+
+// A progressReader reports the number of bytes read so far after every read.
+type progressReader struct {
+	r        io.Reader
+	n        int64
+	progress func(n int64)
+}
+
+func (r *progressReader) Read(p []byte) (n int, err error) {
+	n, err = r.r.Read(p)
+	if n > 0 {
+		r.n += int64(n)
+		r.progress(r.n)
+	}
+	return
+}
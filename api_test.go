@@ -0,0 +1,771 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testAccessToken = "TOKEN"
+
+// A recordedRequest is a request received by a testServer.
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   string
+}
+
+// A testServer is an API server that records each request it receives.
+type testServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+// newTestServer creates a server that responds to every request with the same
+// status and body.
+func newTestServer(t *testing.T, status int, body string) *testServer {
+	return newTestServerFunc(t, func(r *http.Request) (int, string) {
+		return status, body
+	})
+}
+
+// newTestServerFunc creates a server that responds to each request with the
+// status and body returned by respond.
+func newTestServerFunc(t *testing.T, respond func(r *http.Request) (status int, body string)) *testServer {
+	s := &testServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("error reading request body: %v", err)
+		}
+
+		s.mu.Lock()
+		s.requests = append(s.requests, recordedRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Query:  r.URL.Query(),
+			Body:   strings.TrimSpace(string(b)),
+		})
+		s.mu.Unlock()
+
+		status, body := respond(r)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	return s
+}
+
+// Client returns a client that makes requests to the server.
+func (s *testServer) Client() Client {
+	return NewClient(context.Background(), testAccessToken, WithBaseURL(s.URL))
+}
+
+func (s *testServer) Requests() []recordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]recordedRequest(nil), s.requests...)
+}
+
+// readFixture reads a response recorded from the API in the testdata directory.
+func readFixture(t *testing.T, name string) string {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("error reading fixture: %v", err)
+	}
+	return string(b)
+}
+
+func TestGroupsService(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Fixture string // empty for an empty response body
+		Call    func(s GroupsService) (groupID string, err error)
+
+		Method string
+		Path   string
+		Query  url.Values // excluding the access token
+		Body   string
+	}{
+		{
+			Name:    "Index",
+			Fixture: "groups_index.json",
+			Call: func(s GroupsService) (string, error) {
+				groups, err := s.Index(context.Background(), &GroupsIndexOptions{Offset: 1, Limit: 5, Omit: []string{OmitMemberships}})
+				if len(groups) != 1 {
+					return "", err
+				}
+				return groups[0].ID, err
+			},
+			Method: http.MethodGet,
+			Path:   "/groups",
+			Query:  url.Values{"page": {"2"}, "per_page": {"5"}, "omit": {"memberships"}},
+		},
+		{
+			Name:    "IndexPage",
+			Fixture: "groups_index.json",
+			Call: func(s GroupsService) (string, error) {
+				page, err := s.IndexPage(context.Background(), &GroupsIndexOptions{Page: 3})
+				if len(page.Groups) != 1 {
+					return "", err
+				}
+				return page.Groups[0].ID, err
+			},
+			Method: http.MethodGet,
+			Path:   "/groups",
+			Query:  url.Values{"page": {"3"}},
+		},
+		{
+			Name:    "IndexAll",
+			Fixture: "groups_index.json",
+			Call: func(s GroupsService) (string, error) {
+				groups, err := s.IndexAll(context.Background(), &GroupsIndexOptions{Limit: 10})
+				if len(groups) != 1 {
+					return "", err
+				}
+				return groups[0].ID, err
+			},
+			Method: http.MethodGet,
+			Path:   "/groups",
+			Query:  url.Values{"per_page": {"10"}},
+		},
+		{
+			Name:    "Former",
+			Fixture: "groups_index.json",
+			Call: func(s GroupsService) (string, error) {
+				groups, err := s.Former(context.Background())
+				if len(groups) != 1 {
+					return "", err
+				}
+				return groups[0].ID, err
+			},
+			Method: http.MethodGet,
+			Path:   "/groups/former",
+			Query:  url.Values{},
+		},
+		{
+			Name:    "Show",
+			Fixture: "groups_show.json",
+			Call: func(s GroupsService) (string, error) {
+				group, err := s.Show(context.Background(), "1234567890", &GroupsShowOptions{Omit: []string{OmitMemberships}})
+				return group.ID, err
+			},
+			Method: http.MethodGet,
+			Path:   "/groups/1234567890",
+			Query:  url.Values{"omit": {"memberships"}},
+		},
+		{
+			Name:    "Create",
+			Fixture: "groups_show.json",
+			Call: func(s GroupsService) (string, error) {
+				group, err := s.Create(context.Background(), &Group{
+					Name:        "Family",
+					Description: "Coolest Family Ever",
+					Type:        GroupTypePrivate,
+				}, &GroupsCreateOptions{Share: true})
+				return group.ID, err
+			},
+			Method: http.MethodPost,
+			Path:   "/groups",
+			Query:  url.Values{},
+			Body:   `{"name":"Family","description":"Coolest Family Ever","type":"private","share":true}`,
+		},
+		{
+			Name:    "Update",
+			Fixture: "groups_show.json",
+			Call: func(s GroupsService) (string, error) {
+				group, err := s.Update(context.Background(), "1234567890", &Group{
+					Name:     "Family",
+					ImageURL: "https://i.groupme.com/123456789",
+				})
+				return group.ID, err
+			},
+			Method: http.MethodPost,
+			Path:   "/groups/1234567890/update",
+			Query:  url.Values{},
+			Body:   `{"name":"Family","description":"","image_url":"https://i.groupme.com/123456789"}`,
+		},
+		{
+			Name: "Destroy",
+			Call: func(s GroupsService) (string, error) {
+				return "1234567890", s.Destroy(context.Background(), "1234567890")
+			},
+			Method: http.MethodPost,
+			Path:   "/groups/1234567890/destroy",
+			Query:  url.Values{},
+		},
+		{
+			Name:    "Join",
+			Fixture: "groups_join.json",
+			Call: func(s GroupsService) (string, error) {
+				group, err := s.Join(context.Background(), "1234567890", "SHARE_TOKEN")
+				return group.ID, err
+			},
+			Method: http.MethodPost,
+			Path:   "/groups/1234567890/join/SHARE_TOKEN",
+			Query:  url.Values{},
+		},
+		{
+			Name:    "Rejoin",
+			Fixture: "groups_join.json",
+			Call: func(s GroupsService) (string, error) {
+				group, err := s.Rejoin(context.Background(), "1234567890")
+				return group.ID, err
+			},
+			Method: http.MethodPost,
+			Path:   "/groups/join",
+			Query:  url.Values{"group_id": {"1234567890"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var body string
+			if test.Fixture != "" {
+				body = readFixture(t, test.Fixture)
+			}
+			srv := newTestServer(t, http.StatusOK, body)
+			defer srv.Close()
+
+			groupID, err := test.Call(NewGroupsService(srv.Client()))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if groupID != "1234567890" {
+				t.Errorf("expected group ID '1234567890' but got '%s'", groupID)
+			}
+
+			requests := srv.Requests()
+			if len(requests) != 1 {
+				t.Fatalf("expected 1 request but got %d", len(requests))
+			}
+			req := requests[0]
+
+			if req.Method != test.Method {
+				t.Errorf("expected method %s but got %s", test.Method, req.Method)
+			}
+			if req.Path != test.Path {
+				t.Errorf("expected path '%s' but got '%s'", test.Path, req.Path)
+			}
+			if token := req.Query.Get("token"); token != testAccessToken {
+				t.Errorf("expected token '%s' but got '%s'", testAccessToken, token)
+			}
+			req.Query.Del("token")
+			if got, want := req.Query.Encode(), test.Query.Encode(); got != want {
+				t.Errorf("expected query '%s' but got '%s'", want, got)
+			}
+			if req.Body != test.Body {
+				t.Errorf("expected body '%s' but got '%s'", test.Body, req.Body)
+			}
+		})
+	}
+}
+
+func TestDecodeEmptyResponse(t *testing.T) {
+	t.Run("NoContent", func(t *testing.T) {
+		srv := newTestServer(t, http.StatusNoContent, "")
+		defer srv.Close()
+
+		if err := NewLikesService(srv.Client()).Create(context.Background(), "1", "2"); err != nil {
+			t.Errorf("unexpected error liking message: %v", err)
+		}
+		if err := NewGroupsService(srv.Client()).Destroy(context.Background(), "1"); err != nil {
+			t.Errorf("unexpected error destroying group: %v", err)
+		}
+	})
+
+	for _, body := range []string{"", `{"meta":{"code":200},"response":null}`} {
+		t.Run("EmptyOK", func(t *testing.T) {
+			srv := newTestServer(t, http.StatusOK, body)
+			defer srv.Close()
+			ctx := context.Background()
+
+			if _, err := NewGroupsService(srv.Client()).Show(ctx, "1", nil); err != ErrEmptyResponse {
+				t.Errorf("expected ErrEmptyResponse showing group but got %v", err)
+			}
+			if _, err := NewMembersService(srv.Client()).Add(ctx, "1", []Member{{UserID: "1"}}); err != ErrEmptyResponse {
+				t.Errorf("expected ErrEmptyResponse adding members but got %v", err)
+			}
+			if _, err := NewMessagesService(srv.Client()).Create(ctx, "1", "Hello", nil); err != ErrEmptyResponse {
+				t.Errorf("expected ErrEmptyResponse creating message but got %v", err)
+			}
+		})
+	}
+}
+
+// messagesPage returns a messages index response containing messages with the
+// given IDs.
+func messagesPage(ids ...string) string {
+	var messages []string
+	for _, id := range ids {
+		messages = append(messages, `{"id":"`+id+`","group_id":"1","text":"Message `+id+`"}`)
+	}
+	return `{"meta":{"code":200},"response":{"count":10,"messages":[` + strings.Join(messages, ",") + `]}}`
+}
+
+func messageIDs(messages []Message) (ids []string) {
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+	}
+	return
+}
+
+func TestMessagesServiceIndexAllOverlappingPages(t *testing.T) {
+	// Message 9 is repeated on the second page, as if a newer message was
+	// deleted while paginating
+	pages := map[string]string{
+		"":  messagesPage("10", "9", "8"),
+		"8": messagesPage("9", "7", "6"),
+		"6": messagesPage("5"),
+	}
+	srv := newTestServerFunc(t, func(r *http.Request) (int, string) {
+		page, ok := pages[r.URL.Query().Get("before_id")]
+		if !ok {
+			return http.StatusNotFound, ""
+		}
+		return http.StatusOK, page
+	})
+	defer srv.Close()
+
+	messages, err := NewMessagesService(srv.Client()).IndexAll(context.Background(), "1", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids, expected := messageIDs(messages), []string{"10", "9", "8", "7", "6", "5"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected messages %v but got %v", expected, ids)
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("expected 3 requests but got %d", n)
+	}
+}
+
+func TestMessagesServiceRepeatedPage(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK, messagesPage("3", "2", "1"))
+	defer srv.Close()
+	service := NewMessagesService(srv.Client())
+
+	t.Run("IndexAll", func(t *testing.T) {
+		messages, err := service.IndexAll(context.Background(), "1", 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ids, expected := messageIDs(messages), []string{"3", "2", "1"}; !reflect.DeepEqual(ids, expected) {
+			t.Errorf("expected messages %v but got %v", expected, ids)
+		}
+	})
+
+	t.Run("Iterator", func(t *testing.T) {
+		var messages []Message
+		it := service.Iterator(context.Background(), "1", &MessagesIndexOptions{Limit: 3})
+		for it.Next() {
+			messages = append(messages, it.Message())
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ids, expected := messageIDs(messages), []string{"3", "2", "1"}; !reflect.DeepEqual(ids, expected) {
+			t.Errorf("expected messages %v but got %v", expected, ids)
+		}
+	})
+}
+
+func TestBatchErrorOrder(t *testing.T) {
+	var members []Member
+	for i := 0; i < 11; i++ {
+		members = append(members, Member{UserID: "1"})
+	}
+	members[2].UserID = ""
+	members[10].UserID = ""
+
+	_, err := NewMembersService(NewCaptureClient()).Add(context.Background(), "1", members)
+	expected := "MembersService.Add: 2 of 11 failed: 2: requires a user ID, phone number, or email (and 1 more)"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error '%s' but got '%v'", expected, err)
+	}
+}
+
+func TestCachingClientClear(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK, readFixture(t, "groups_show.json"))
+	defer srv.Close()
+	c := NewCachingClient(NewClient(context.Background(), testAccessToken, WithBaseURL(srv.URL+"/v3"), WithPushURL(srv.URL+"/faye")), time.Minute)
+
+	show := func() {
+		if _, err := NewGroupsService(c).Show(context.Background(), "1234567890", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	post := func(url string) {
+		req, err := http.NewRequest(http.MethodPost, url, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	show()
+	post(srv.URL + "/faye")
+	show()
+	if n := len(srv.Requests()); n != 2 {
+		t.Errorf("expected push request to leave the cache intact, got %d requests", n)
+	}
+
+	post(srv.URL + "/v3/groups/1234567890/update")
+	show()
+	if n := len(srv.Requests()); n != 4 {
+		t.Errorf("expected API request to clear the cache, got %d requests", n)
+	}
+}
+
+func TestMessageIterator(t *testing.T) {
+	pages := map[string]string{
+		"":  messagesPage("10", "9", "8"),
+		"8": messagesPage("7", "6", "5"),
+		"5": messagesPage("4"),
+	}
+	srv := newTestServerFunc(t, func(r *http.Request) (int, string) {
+		page, ok := pages[r.URL.Query().Get("before_id")]
+		if !ok {
+			return http.StatusNotFound, ""
+		}
+		return http.StatusOK, page
+	})
+	defer srv.Close()
+
+	var messages []Message
+	it := NewMessagesService(srv.Client()).Iterator(context.Background(), "1", &MessagesIndexOptions{Limit: 3, SinceID: "5"})
+	for it.Next() {
+		messages = append(messages, it.Message())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids, expected := messageIDs(messages), []string{"10", "9", "8", "7", "6"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected messages %v but got %v", expected, ids)
+	}
+	if n := len(srv.Requests()); n != 2 {
+		t.Errorf("expected 2 requests but got %d", n)
+	}
+}
+
+// directMessagesPage returns a direct messages index response containing
+// messages with the given IDs.
+func directMessagesPage(ids ...string) string {
+	var messages []string
+	for _, id := range ids {
+		messages = append(messages, `{"id":"`+id+`","recipient_id":"2","user_id":"1","text":"Message `+id+`"}`)
+	}
+	return `{"meta":{"code":200},"response":{"count":10,"direct_messages":[` + strings.Join(messages, ",") + `]}}`
+}
+
+func iterateDirectMessages(t *testing.T, it *DirectMessageIterator) (ids []string) {
+	for it.Next() {
+		ids = append(ids, it.Message().ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return
+}
+
+func TestDirectMessageIterator(t *testing.T) {
+	t.Run("Pages", func(t *testing.T) {
+		pages := map[string]string{
+			"":  directMessagesPage("6", "5", "4"),
+			"4": directMessagesPage("3", "2", "1"),
+			"1": directMessagesPage(),
+		}
+		srv := newTestServerFunc(t, func(r *http.Request) (int, string) {
+			page, ok := pages[r.URL.Query().Get("before_id")]
+			if !ok {
+				return http.StatusNotFound, ""
+			}
+			return http.StatusOK, page
+		})
+		defer srv.Close()
+
+		it := NewDirectMessagesService(srv.Client()).Iterator(context.Background(), "2", nil)
+		if ids, expected := iterateDirectMessages(t, it), []string{"6", "5", "4", "3", "2", "1"}; !reflect.DeepEqual(ids, expected) {
+			t.Errorf("expected messages %v but got %v", expected, ids)
+		}
+	})
+
+	t.Run("RepeatedPage", func(t *testing.T) {
+		srv := newTestServer(t, http.StatusOK, directMessagesPage("3", "2", "1"))
+		defer srv.Close()
+
+		it := NewDirectMessagesService(srv.Client()).Iterator(context.Background(), "2", nil)
+		if ids, expected := iterateDirectMessages(t, it), []string{"3", "2", "1"}; !reflect.DeepEqual(ids, expected) {
+			t.Errorf("expected messages %v but got %v", expected, ids)
+		}
+		if n := len(srv.Requests()); n != 2 {
+			t.Errorf("expected 2 requests but got %d", n)
+		}
+	})
+}
+
+func TestImageServiceUpload(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK, `{"payload":{"url":"https://i.groupme.com/123456789","picture_url":"https://i.groupme.com/123456789"}}`)
+	defer srv.Close()
+	c := NewClient(context.Background(), testAccessToken, WithImageServiceURL(srv.URL))
+
+	imageURL, err := NewImageService(c).Upload(context.Background(), strings.NewReader("GIF89a"), "image/gif")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imageURL != "https://i.groupme.com/123456789" {
+		t.Errorf("expected image URL 'https://i.groupme.com/123456789' but got '%s'", imageURL)
+	}
+	if req := srv.Requests()[0]; req.Method != http.MethodPost || req.Path != "/pictures" || req.Body != "GIF89a" {
+		t.Errorf("unexpected request %s %s with body '%s'", req.Method, req.Path, req.Body)
+	}
+}
+
+func TestImageServiceUploadNoURL(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK, `{"payload":{}}`)
+	defer srv.Close()
+	c := NewClient(context.Background(), testAccessToken, WithImageServiceURL(srv.URL), WithBaseURL(srv.URL))
+
+	if _, err := NewImageService(c).Upload(context.Background(), strings.NewReader("GIF89a"), "image/gif"); err == nil {
+		t.Errorf("expected error for a response without a URL")
+	}
+	if _, err := NewMessagesService(c).CreateWithImage(context.Background(), "1", "Hello", strings.NewReader("GIF89a"), "image/gif"); err == nil {
+		t.Errorf("expected error creating a message with an image without a URL")
+	}
+	if n := len(srv.Requests()); n != 2 {
+		t.Errorf("expected no message to be created, got %d requests", n)
+	}
+}
+
+func TestMembersServiceAdd(t *testing.T) {
+	t.Run("ResultsID", func(t *testing.T) {
+		srv := newTestServer(t, http.StatusAccepted, `{"meta":{"code":202},"response":{"results_id":"GUID"}}`)
+		defer srv.Close()
+
+		resultID, err := NewMembersService(srv.Client()).Add(context.Background(), "1", []Member{{Nickname: "Jane", UserID: "1"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resultID != "GUID" {
+			t.Errorf("expected results ID 'GUID' but got '%s'", resultID)
+		}
+		if req := srv.Requests()[0]; req.Path != "/groups/1/members/add" || req.Body != `{"members":[{"nickname":"Jane","user_id":"1"}]}` {
+			t.Errorf("unexpected request to '%s' with body '%s'", req.Path, req.Body)
+		}
+	})
+
+	t.Run("NoResultsID", func(t *testing.T) {
+		srv := newTestServer(t, http.StatusAccepted, `{"meta":{"code":202},"response":{}}`)
+		defer srv.Close()
+
+		if _, err := NewMembersService(srv.Client()).Add(context.Background(), "1", []Member{{UserID: "1"}}); err == nil {
+			t.Errorf("expected error for a response without a results ID")
+		}
+	})
+}
+
+// A roundTripperFunc is a transport implemented by a function.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestWithConnectionPool(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK, `{"response":{"id":"1"}}`)
+	defer srv.Close()
+
+	var trips int
+	wrapping := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		trips++
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	tests := []struct {
+		Name    string
+		Options []ClientOption
+		Pooled  bool // whether the pool settings are applied
+		Trips   int  // requests made through the wrapping transport
+	}{
+		{"Default", []ClientOption{WithConnectionPool(50, 10)}, true, 0},
+		{"BeforeTransport", []ClientOption{WithConnectionPool(50, 10), WithTransport(wrapping)}, false, 1},
+		{"AfterTransport", []ClientOption{WithTransport(wrapping), WithConnectionPool(50, 10)}, false, 1},
+		{"AfterHTTPTransport", []ClientOption{WithTransport(&http.Transport{}), WithConnectionPool(50, 10)}, true, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			trips = 0
+			c := NewClient(context.Background(), testAccessToken, append([]ClientOption{WithBaseURL(srv.URL)}, test.Options...)...)
+
+			transport, ok := c.(*client).client.Transport.(*http.Transport)
+			if pooled := ok && transport.MaxIdleConns == 50 && transport.MaxIdleConnsPerHost == 10; pooled != test.Pooled {
+				t.Errorf("expected pool settings applied to be %v but got %v", test.Pooled, pooled)
+			}
+
+			if _, err := NewGroupsService(c).Show(context.Background(), "1", nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if trips != test.Trips {
+				t.Errorf("expected %d requests through the wrapping transport but got %d", test.Trips, trips)
+			}
+		})
+	}
+
+	if _, ok := http.DefaultClient.Transport.(*http.Transport); ok {
+		t.Errorf("expected the default client not to be modified")
+	}
+}
+
+func TestWithLoggerRedactsAccessToken(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK, `{"response":{"id":"1"}}`)
+	defer srv.Close()
+
+	type logged struct {
+		Req  *http.Request
+		Resp *http.Response
+		Err  error
+	}
+	var logs []logged
+	logger := WithLogger(func(req *http.Request, resp *http.Response, err error) {
+		logs = append(logs, logged{req, resp, err})
+	})
+	failing := WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}))
+
+	for _, c := range []Client{
+		NewClient(context.Background(), testAccessToken, WithBaseURL(srv.URL), logger),
+		NewClient(context.Background(), testAccessToken, WithBaseURL(srv.URL), logger, failing),
+	} {
+		NewGroupsService(c).Show(context.Background(), "1", nil)
+	}
+
+	if len(logs) != 2 {
+		t.Fatalf("expected 2 logged requests but got %d", len(logs))
+	}
+	for i, l := range logs {
+		if strings.Contains(l.Req.URL.String(), testAccessToken) {
+			t.Errorf("request %d: logged URL contains the access token: %s", i, l.Req.URL)
+		}
+		if v := l.Req.Header.Get("X-Access-Token"); v != "" {
+			t.Errorf("request %d: logged header contains the access token: %s", i, v)
+		}
+		if l.Resp != nil && strings.Contains(l.Resp.Request.URL.String(), testAccessToken) {
+			t.Errorf("request %d: logged response's request URL contains the access token: %s", i, l.Resp.Request.URL)
+		}
+		if l.Err != nil && strings.Contains(l.Err.Error(), testAccessToken) {
+			t.Errorf("request %d: logged error contains the access token: %v", i, l.Err)
+		}
+	}
+	if logs[0].Resp == nil || logs[0].Err != nil {
+		t.Errorf("expected the first request to succeed but got %v", logs[0].Err)
+	}
+	if logs[1].Err == nil || !strings.Contains(logs[1].Err.Error(), "connection refused") {
+		t.Errorf("expected the second request's error to be logged but got %v", logs[1].Err)
+	}
+
+	// Only the logged copies are redacted
+	if requests := srv.Requests(); len(requests) != 1 || requests[0].Query.Get("token") != testAccessToken {
+		t.Errorf("expected the access token to be sent but got %+v", requests)
+	}
+}
+
+func TestMembersServiceAddResults(t *testing.T) {
+	srv := newTestServer(t, http.StatusOK, `{"meta":{"code":200},"response":{"members":[
+		{"id":"1","user_id":"1","nickname":"Jane","guid":"GUID-1"},
+		{"id":"2","user_id":"2","nickname":"John","guid":"GUID-2","autokicked":true}
+	]}}`)
+	defer srv.Close()
+
+	result, err := NewMembersService(srv.Client()).AddResults(context.Background(), "1", "RESULTS")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Added) != 1 || result.Added[0].GUID != "GUID-1" {
+		t.Errorf("unexpected added members: %+v", result.Added)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].GUID != "GUID-2" {
+		t.Errorf("unexpected failed members: %+v", result.Failed)
+	}
+
+	missing := result.Missing([]Member{{GUID: "GUID-1"}, {GUID: "GUID-2"}, {GUID: "GUID-3"}, {Nickname: "No GUID"}})
+	if len(missing) != 1 || missing[0].GUID != "GUID-3" {
+		t.Errorf("unexpected missing members: %+v", missing)
+	}
+	if req := srv.Requests()[0]; req.Path != "/groups/1/members/results/RESULTS" {
+		t.Errorf("unexpected request to '%s'", req.Path)
+	}
+}
+
+// A wrappingClient wraps the errors returned by another client, like a
+// middleware adding context to them might.
+type wrappingClient struct {
+	clientWrapper
+}
+
+func (c wrappingClient) Do(req *http.Request) (resp *http.Response, err error) {
+	resp, err = c.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("wrapped: %w", err)
+	}
+	return
+}
+
+func TestGroupsServiceRejoinNotFormer(t *testing.T) {
+	srv := newTestServerFunc(t, func(r *http.Request) (int, string) {
+		if r.URL.Path == "/groups/former" {
+			return http.StatusOK, `{"meta":{"code":200},"response":[{"id":"2"}]}`
+		}
+		return http.StatusNotFound, `{"meta":{"code":404,"errors":["not found"]},"response":null}`
+	})
+	defer srv.Close()
+
+	for _, c := range []Client{srv.Client(), wrappingClient{clientWrapper{srv.Client()}}} {
+		if _, err := NewGroupsService(c).Rejoin(context.Background(), "1"); err != ErrNotFormerGroup {
+			t.Errorf("%T: expected ErrNotFormerGroup but got %v", c, err)
+		}
+
+		// The API error is kept for a former group
+		_, err := NewGroupsService(c).Rejoin(context.Background(), "2")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("%T: expected ErrNotFound but got %v", c, err)
+		}
+	}
+}
+
+func TestDirectMessagesServiceCreate(t *testing.T) {
+	srv := newTestServer(t, http.StatusCreated, `{"meta":{"code":201},"response":{"direct_message":{"id":"1","recipient_id":"2","text":"Hello"}}}`)
+	defer srv.Close()
+
+	message, err := NewDirectMessagesService(srv.Client()).Create(context.Background(), "2", "Hello", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if message.ID != "1" || message.RecipientID != "2" {
+		t.Errorf("unexpected message: %+v", message)
+	}
+
+	req := srv.Requests()[0]
+	if req.Method != http.MethodPost || req.Path != "/direct_messages" || !strings.Contains(req.Body, `"recipient_id":"2"`) {
+		t.Errorf("unexpected request %s %s with body '%s'", req.Method, req.Path, req.Body)
+	}
+}
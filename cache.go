@@ -0,0 +1,161 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A CachingClient is a Client that caches the responses to successful GET
+// requests for a fixed time, keyed by URL, so repeated requests for the same
+// resource, e.g. showing a group, don't each reach the server. Any other
+// request, such as a POST updating a group, is always sent, and if it is an API
+// request it clears the cache so stale responses aren't returned after a
+// change.
+type CachingClient struct {
+	clientWrapper
+	ttl   time.Duration
+	cache *responseCache
+}
+
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// NewCachingClient creates a client that caches the responses to GET requests
+// made with c for the given time.
+func NewCachingClient(c Client, ttl time.Duration) *CachingClient {
+	return &CachingClient{
+		clientWrapper: clientWrapper{client: c},
+		ttl:           ttl,
+		cache: &responseCache{
+			entries: make(map[string]cachedResponse),
+		},
+	}
+}
+
+// Do makes an API request, returning a cached response if there is one.
+func (c *CachingClient) Do(req *http.Request) (resp *http.Response, err error) {
+	if err = req.Context().Err(); err != nil {
+		return
+	}
+
+	if req.Method != http.MethodGet {
+		// Only API requests can change the cached resources, requests to the
+		// image and push services, e.g. long polls, leave the cache intact
+		if strings.HasPrefix(req.URL.String(), baseURL(c.client)+"/") {
+			c.Clear()
+		}
+		return c.client.Do(req)
+	}
+
+	key := req.URL.String()
+	if cached, ok := c.cache.get(key); ok {
+		resp = cached.response(req)
+		return
+	}
+
+	resp, err = c.client.Do(req)
+	if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return
+	}
+
+	// Read the body so it can be cached, returning a copy
+	var body []byte
+	body, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	c.cache.set(key, cachedResponse{
+		status:  resp.StatusCode,
+		header:  resp.Header.Clone(),
+		body:    body,
+		expires: time.Now().Add(c.ttl),
+	})
+	return
+}
+
+// Clear removes all cached responses.
+func (c *CachingClient) Clear() {
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+	c.cache.entries = make(map[string]cachedResponse)
+}
+
+func (rc *responseCache) get(key string) (cached cachedResponse, ok bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	cached, ok = rc.entries[key]
+	if ok && time.Now().After(cached.expires) {
+		delete(rc.entries, key)
+		ok = false
+	}
+	return
+}
+
+func (rc *responseCache) set(key string, cached cachedResponse) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	// Remove any expired responses so the cache doesn't grow without bound
+	now := time.Now()
+	for k, e := range rc.entries {
+		if now.After(e.expires) {
+			delete(rc.entries, k)
+		}
+	}
+	rc.entries[key] = cached
+}
+
+// response creates a response to the request from the cached response.
+func (cached cachedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cached.status, http.StatusText(cached.status)),
+		StatusCode:    cached.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cached.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(cached.body)),
+		ContentLength: int64(len(cached.body)),
+		Request:       req,
+	}
+}
+
+// SetAccessToken sets the access token of the wrapped client, if it supports
+// changing it, and clears the cache since the responses may differ for the new
+// token.
+func (c *CachingClient) SetAccessToken(accessToken string) {
+	c.clientWrapper.SetAccessToken(accessToken)
+	c.Clear()
+}
+
+// WithContext returns a new caching client sharing the same cache and wrapping
+// the wrapped client bound to the given context, if it supports binding one,
+// see ContextBinder.
+func (c *CachingClient) WithContext(ctx context.Context) Client {
+	caching := *c
+	caching.clientWrapper = c.withContext(ctx)
+	return &caching
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// A CapturedRequest is a request recorded by a CaptureClient.
+type CapturedRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// A CaptureClient is a Client that records requests instead of sending them,
+// responding to each with an empty JSON object and a 200 OK status, so service
+// methods that return a value return ErrEmptyResponse. It is useful for testing
+// the requests made by code that uses the services.
+type CaptureClient struct {
+	mu       sync.Mutex
+	requests []CapturedRequest
+}
+
+// NewCaptureClient creates a client that captures requests.
+func NewCaptureClient() *CaptureClient {
+	return &CaptureClient{}
+}
+
+// Do records the request and returns a canned response.
+func (c *CaptureClient) Do(req *http.Request) (resp *http.Response, err error) {
+	captured := CapturedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+	}
+	if req.Body != nil {
+		captured.Body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return
+		}
+	}
+
+	c.mu.Lock()
+	c.requests = append(c.requests, captured)
+	c.mu.Unlock()
+
+	resp = &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte("{}"))),
+		ContentLength: 2,
+		Request:       req,
+	}
+	return
+}
+
+// Requests returns the requests captured so far in the order they were made.
+func (c *CaptureClient) Requests() []CapturedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]CapturedRequest(nil), c.requests...)
+}
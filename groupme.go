@@ -0,0 +1,55 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import "context"
+
+// A GroupMe provides access to all the services using a shared Client.
+type GroupMe struct {
+	Client Client
+
+	Groups         GroupsService
+	Members        MembersService
+	Messages       MessagesService
+	Chats          ChatsService
+	DirectMessages DirectMessagesService
+	Likes          LikesService
+	Reactions      ReactionsService
+	Leaderboard    LeaderboardService
+	Bots           BotsService
+	Users          UsersService
+	SMS            SmsService
+	Blocks         BlocksService
+	Images         ImageService
+	Push           PushService
+}
+
+// New creates a GroupMe with a client created by NewClient from the given
+// context, access token, and options.
+func New(ctx context.Context, accessToken string, options ...ClientOption) *GroupMe {
+	return NewWithClient(NewClient(ctx, accessToken, options...), accessToken)
+}
+
+// NewWithClient creates a GroupMe using the given client. The access token is
+// required by the push service.
+func NewWithClient(client Client, accessToken string) *GroupMe {
+	return &GroupMe{
+		Client:         client,
+		Groups:         NewGroupsService(client),
+		Members:        NewMembersService(client),
+		Messages:       NewMessagesService(client),
+		Chats:          NewChatsService(client),
+		DirectMessages: NewDirectMessagesService(client),
+		Likes:          NewLikesService(client),
+		Reactions:      NewReactionsService(client),
+		Leaderboard:    NewLeaderboardService(client),
+		Bots:           NewBotsService(client),
+		Users:          NewUsersService(client),
+		SMS:            NewSmsService(client),
+		Blocks:         NewBlocksService(client),
+		Images:         NewImageService(client),
+		Push:           NewPushService(client, accessToken),
+	}
+}
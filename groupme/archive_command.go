@@ -0,0 +1,68 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jlubawy/go-cli"
+	"github.com/jlubawy/go-groupme"
+)
+
+var archiveOptions struct {
+	SinceID string
+}
+
+var archiveCommand = cli.Command{
+	Name:             "archive",
+	ShortDescription: "dump the message history of a group",
+	Description: `Dump the entire message history of a group to stdout as newline-delimited
+JSON, one message per line, most recent first. Progress is printed to stderr.`,
+	ShortUsage: "[-since=ID] [group ID]",
+	SetupFlags: func(fs *flag.FlagSet) {
+		fs.StringVar(&archiveOptions.SinceID, "since", "", "only archive messages created after the given message ID")
+	},
+	Run: func(args []string) {
+		if len(args) == 0 {
+			cli.Fatal("Must provide a group ID.\n")
+		} else if len(args) > 1 {
+			cli.Fatal("Multiple group IDs provided.\n")
+		}
+
+		client := groupme.NewClient(context.Background(), AccessToken)
+		service := groupme.NewMessagesService(client)
+
+		w := bufio.NewWriter(os.Stdout)
+		enc := json.NewEncoder(w)
+
+		it := service.Iterator(context.Background(), args[0], &groupme.MessagesIndexOptions{
+			SinceID: archiveOptions.SinceID,
+			Limit:   100,
+		})
+		n := 0
+		for it.Next() {
+			message := it.Message()
+			if err := enc.Encode(&message); err != nil {
+				cli.Fatalf("Error encoding message: %v\n", err)
+			}
+			n++
+			if n%100 == 0 {
+				fmt.Fprintf(os.Stderr, "Archived %d messages\n", n)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			cli.Fatalf("Error writing messages: %v\n", err)
+		}
+		if err := it.Err(); err != nil {
+			cli.Fatalf("Error indexing messages: %v\n", err)
+		}
+		fmt.Fprintf(os.Stderr, "Archived %d messages in total\n", n)
+	},
+}
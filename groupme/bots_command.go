@@ -0,0 +1,133 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/jlubawy/go-cli"
+	"github.com/jlubawy/go-groupme"
+)
+
+var botsOptions struct {
+	Compact bool
+}
+
+var botsCommand = cli.Command{
+	Name:             "bots",
+	ShortDescription: "list and manage bots",
+	Description: `List and manage the bots created by the authenticated user.
+
+Subcommands:
+  list                                                    list bots as JSON
+  create [-avatar=URL] [-callback=URL] [group ID] [name]  create a bot and print its ID
+  post [-image=URL] [bot ID] [text]                       post a message as a bot
+  destroy [bot ID]                                        destroy a bot`,
+	ShortUsage: "[-compact=false] [list|create|post|destroy] [arguments]",
+	SetupFlags: func(fs *flag.FlagSet) {
+		fs.BoolVar(&botsOptions.Compact, "compact", false, "output compact JSON")
+	},
+	Run: func(args []string) {
+		if len(args) == 0 {
+			cli.Fatal("Must provide a subcommand.\n")
+		}
+
+		client := groupme.NewClient(context.Background(), AccessToken)
+		service := groupme.NewBotsService(client)
+
+		switch args[0] {
+		case "list":
+			botsList(service, args[1:])
+		case "create":
+			botsCreate(service, args[1:])
+		case "post":
+			botsPost(service, args[1:])
+		case "destroy":
+			botsDestroy(service, args[1:])
+		default:
+			cli.Fatalf("Unknown subcommand '%s'.\n", args[0])
+		}
+	},
+}
+
+func botsList(service groupme.BotsService, args []string) {
+	if len(args) > 0 {
+		cli.Fatal("Too many arguments.\n")
+	}
+
+	bots, err := service.Index(context.Background())
+	if err != nil {
+		cli.Fatalf("Error indexing bots: %v\n", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	if !botsOptions.Compact {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(&bots); err != nil {
+		cli.Fatalf("Error encoding bots: %v\n", err)
+	}
+}
+
+func botsCreate(service groupme.BotsService, args []string) {
+	var bot groupme.Bot
+
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	fs.StringVar(&bot.AvatarURL, "avatar", "", "the URL of the bot's avatar image")
+	fs.StringVar(&bot.CallbackURL, "callback", "", "the URL messages in the bot's group are posted to")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		cli.Fatal("Must provide a group ID and bot name.\n")
+	}
+	bot.GroupID = fs.Arg(0)
+	bot.Name = fs.Arg(1)
+
+	created, err := service.Create(context.Background(), &bot)
+	if err != nil {
+		cli.Fatalf("Error creating bot: %v\n", err)
+	}
+
+	fmt.Println(created.BotID)
+}
+
+func botsPost(service groupme.BotsService, args []string) {
+	var imageURL string
+
+	fs := flag.NewFlagSet("post", flag.ExitOnError)
+	fs.StringVar(&imageURL, "image", "", "attach an image hosted by the GroupMe image service")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		cli.Fatal("Must provide a bot ID.\n")
+	} else if fs.NArg() == 1 && imageURL == "" {
+		cli.Fatal("Must provide text or an image.\n")
+	} else if fs.NArg() > 2 {
+		cli.Fatal("Too many arguments, quote the message text.\n")
+	}
+
+	var attachments []groupme.Attachment
+	if imageURL != "" {
+		attachments = append(attachments, groupme.NewImageAttachment(imageURL))
+	}
+
+	if err := service.PostMessage(context.Background(), fs.Arg(0), fs.Arg(1), attachments); err != nil {
+		cli.Fatalf("Error posting message: %v\n", err)
+	}
+}
+
+func botsDestroy(service groupme.BotsService, args []string) {
+	if len(args) != 1 {
+		cli.Fatal("Must provide a bot ID.\n")
+	}
+
+	if err := service.Destroy(context.Background(), args[0]); err != nil {
+		cli.Fatalf("Error destroying bot: %v\n", err)
+	}
+}
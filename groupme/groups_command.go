@@ -33,7 +33,7 @@ var groupsCommand = cli.Command{
 		client := groupme.NewClient(context.Background(), AccessToken)
 
 		service := groupme.NewGroupsService(client)
-		groups, err := service.Index(&groupsOptions.GroupsIndexOptions)
+		groups, err := service.Index(context.Background(), &groupsOptions.GroupsIndexOptions)
 		if err != nil {
 			cli.Fatalf("Error indexing groups: %+v\n", err)
 		}
@@ -25,8 +25,12 @@ var program = cli.Program{
 	Name:        "groupme",
 	Description: "GroupMe is a command-line tool for accessing the GroupMe API.",
 	Commands: []cli.Command{
+		archiveCommand,
+		botsCommand,
 		groupsCommand,
 		messagesCommand,
+		postCommand,
+		verifyCommand,
 	},
 }
 
@@ -5,9 +5,11 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"flag"
+	"io"
 	"os"
 
 	"github.com/jlubawy/go-cli"
@@ -17,19 +19,23 @@ import (
 var messagesOptions = struct {
 	groupme.MessagesIndexOptions
 	Compact bool
+	NDJSON  bool
+	Resolve bool
 }{}
 
 var messagesCommand = cli.Command{
 	Name:             "messages",
 	ShortDescription: "query messages from a particular group",
 	Description:      `Query messages from a particular group.`,
-	ShortUsage:       "[group ID]",
+	ShortUsage:       "[-limit=20] [-resolve=false] [-ndjson=false] [group ID]",
 	SetupFlags: func(fs *flag.FlagSet) {
 		fs.StringVar(&messagesOptions.BeforeID, "before", "", "returns messages created before the given message ID")
 		fs.StringVar(&messagesOptions.SinceID, "since", "", "returns most recent messages created after the given message ID")
 		fs.StringVar(&messagesOptions.AfterID, "after", "", "returns messages created immediately after the given message ID")
-		fs.IntVar(&messagesOptions.Limit, "limit", 0, "limit the number of messages returned, the maximum is 100")
+		fs.IntVar(&messagesOptions.Limit, "limit", 20, "limit the number of messages returned, 0 for all of them")
 		fs.BoolVar(&messagesOptions.Compact, "compact", false, "output compact JSON")
+		fs.BoolVar(&messagesOptions.NDJSON, "ndjson", false, "output newline-delimited JSON, one message per line")
+		fs.BoolVar(&messagesOptions.Resolve, "resolve", false, "add the nicknames of users who liked or are mentioned in each message")
 	},
 	Run: func(args []string) {
 		if len(args) == 0 {
@@ -40,18 +46,151 @@ var messagesCommand = cli.Command{
 
 		client := groupme.NewClient(context.Background(), AccessToken)
 
-		service := groupme.NewMessagesService(client)
-		messages, err := service.Index(args[0], &messagesOptions.MessagesIndexOptions)
-		if err != nil {
-			cli.Fatalf("Error indexing messages: %v\n", err)
+		var resolve func(m groupme.Message) resolvedMessage
+		if messagesOptions.Resolve {
+			group, err := groupme.NewGroupsService(client).Show(context.Background(), args[0], nil)
+			if err != nil {
+				cli.Fatalf("Error showing group: %v\n", err)
+			}
+			resolve = newResolver(group.Members)
+		}
+
+		// The limit is the total number of messages, request as few pages of
+		// the largest size allowed as needed
+		limit := messagesOptions.Limit
+		options := messagesOptions.MessagesIndexOptions
+		if limit <= 0 || limit > 100 {
+			options.Limit = 100
 		}
 
-		enc := json.NewEncoder(os.Stdout)
-		if !messagesOptions.Compact {
-			enc.SetIndent("", "  ")
+		service := groupme.NewMessagesService(client)
+		it := service.Iterator(context.Background(), args[0], &options)
+
+		w := bufio.NewWriter(os.Stdout)
+		var flushErr error
+		n := 0
+		next := func() (interface{}, bool) {
+			if limit > 0 && n >= limit {
+				return nil, false
+			}
+
+			// Write what has been encoded so far before possibly waiting on the
+			// next page of messages
+			if flushErr = w.Flush(); flushErr != nil {
+				return nil, false
+			}
+			if !it.Next() {
+				return nil, false
+			}
+			n++
+
+			m := it.Message()
+			if resolve != nil {
+				r := resolve(m)
+				return &r, true
+			}
+			return &m, true
 		}
-		if err := enc.Encode(&messages); err != nil {
+
+		if err := encodeStream(w, next, messagesOptions.Compact, messagesOptions.NDJSON); err != nil {
 			cli.Fatalf("Error encoding messages: %v\n", err)
 		}
+		if err := it.Err(); err != nil {
+			cli.Fatalf("Error indexing messages: %v\n", err)
+		}
+		if flushErr == nil {
+			flushErr = w.Flush()
+		}
+		if flushErr != nil {
+			cli.Fatalf("Error writing messages: %v\n", flushErr)
+		}
 	},
 }
+
+// A resolvedMessage is a message annotated with the nicknames of the users
+// that liked it or are mentioned in it.
+type resolvedMessage struct {
+	groupme.Message
+	FavoritedByNicknames []string `json:"favorited_by_nicknames,omitempty"`
+	MentionedNicknames   []string `json:"mentioned_nicknames,omitempty"`
+}
+
+// newResolver returns a function that resolves the user IDs of a message to
+// the nicknames of the given members, falling back to the user ID for users
+// that aren't members.
+func newResolver(members []groupme.Member) func(m groupme.Message) resolvedMessage {
+	nicknames := make(map[string]string)
+	for _, m := range members {
+		nicknames[m.UserID] = m.Nickname
+	}
+	nickname := func(userID string) string {
+		if n, ok := nicknames[userID]; ok {
+			return n
+		}
+		return userID
+	}
+
+	return func(m groupme.Message) (resolved resolvedMessage) {
+		resolved.Message = m
+		for _, userID := range m.FavoritedBy {
+			resolved.FavoritedByNicknames = append(resolved.FavoritedByNicknames, nickname(userID))
+		}
+		for _, mention := range m.Mentions() {
+			resolved.MentionedNicknames = append(resolved.MentionedNicknames, nickname(mention.UserID))
+		}
+		return
+	}
+}
+
+// encodeStream encodes each value returned by next as it is returned, either
+// as the elements of a JSON array or as newline-delimited JSON, so the values
+// never need to be held in memory at once.
+func encodeStream(w io.Writer, next func() (interface{}, bool), compact, ndjson bool) error {
+	if ndjson {
+		enc := json.NewEncoder(w)
+		for v, ok := next(); ok; v, ok = next() {
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	n := 0
+	for v, ok := next(); ok; v, ok = next() {
+		var b []byte
+		var err error
+		if compact {
+			b, err = json.Marshal(v)
+		} else {
+			b, err = json.MarshalIndent(v, "  ", "  ")
+		}
+		if err != nil {
+			return err
+		}
+
+		sep := ""
+		if n > 0 {
+			sep = ","
+		}
+		if !compact {
+			sep += "\n  "
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+		n++
+	}
+	end := "]\n"
+	if !compact && n > 0 {
+		end = "\n]\n"
+	}
+	_, err := io.WriteString(w, end)
+	return err
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/jlubawy/go-cli"
+	"github.com/jlubawy/go-groupme"
+)
+
+var postOptions struct {
+	ImageURL   string
+	SourceGUID string
+}
+
+var postCommand = cli.Command{
+	Name:             "post",
+	ShortDescription: "post a message to a particular group",
+	Description: `Post a message to a particular group and print the ID of the created message.
+
+Setting the source GUID makes posting idempotent, e.g. when retrying a failed
+command in a script: GroupMe doesn't post a message with the same source GUID
+as an earlier message in the group again.`,
+	ShortUsage: "[-image=URL] [-guid=GUID] [group ID] [text]",
+	SetupFlags: func(fs *flag.FlagSet) {
+		fs.StringVar(&postOptions.ImageURL, "image", "", "attach an image hosted by the GroupMe image service")
+		fs.StringVar(&postOptions.SourceGUID, "guid", "", "the source GUID of the message, messages with a GUID already used in the group are not posted again (default random)")
+	},
+	Run: func(args []string) {
+		if len(args) == 0 {
+			cli.Fatal("Must provide a group ID.\n")
+		} else if len(args) == 1 && postOptions.ImageURL == "" {
+			cli.Fatal("Must provide text or an image.\n")
+		} else if len(args) > 2 {
+			cli.Fatal("Too many arguments, quote the message text.\n")
+		}
+
+		var text string
+		if len(args) == 2 {
+			text = args[1]
+		}
+
+		var attachments []groupme.Attachment
+		if postOptions.ImageURL != "" {
+			attachments = append(attachments, groupme.NewImageAttachment(postOptions.ImageURL))
+		}
+
+		client := groupme.NewClient(context.Background(), AccessToken)
+
+		service := groupme.NewMessagesService(client)
+		message, err := service.CreateWithGUID(context.Background(), args[0], postOptions.SourceGUID, text, attachments)
+		if err != nil {
+			cli.Fatalf("Error posting message: %v\n", err)
+		}
+
+		fmt.Println(message.ID)
+	},
+}
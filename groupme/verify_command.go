@@ -0,0 +1,36 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/jlubawy/go-cli"
+	"github.com/jlubawy/go-groupme"
+)
+
+var verifyCommand = cli.Command{
+	Name:             "verify",
+	ShortDescription: "verify the access token",
+	Description: fmt.Sprintf(`Verify the access token set by the '%s' environment variable is valid.
+
+Exits with a non-zero status if the token is rejected or can't be checked.`, AccessTokenKey),
+	ShortUsage: "",
+	SetupFlags: func(fs *flag.FlagSet) {},
+	Run: func(args []string) {
+		client := groupme.NewClient(context.Background(), AccessToken)
+
+		err := groupme.NewUsersService(client).Verify(context.Background())
+		if errors.Is(err, groupme.ErrUnauthorized) {
+			cli.Fatalf("Invalid access token, check the '%s' environment variable.\n", AccessTokenKey)
+		} else if err != nil {
+			cli.Fatalf("Error verifying access token: %v\n", err)
+		}
+		fmt.Println("Access token is valid.")
+	},
+}
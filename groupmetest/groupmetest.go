@@ -0,0 +1,202 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package groupmetest provides utilities for testing code that uses package
+groupme, in the style of net/http/httptest.
+
+A Client returns canned responses for the endpoints registered with Handle,
+which are matched against the path of each request:
+
+	client := groupmetest.NewClient()
+	client.Handle(http.MethodGet, "/groups/*", http.StatusOK, groupmetest.Envelope(groupmetest.GroupJSON))
+
+	group, err := groupme.NewGroupsService(client).Show(ctx, "1234567", nil)
+*/
+package groupmetest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sync"
+
+	"github.com/jlubawy/go-groupme"
+)
+
+// URL is the base URL used for all requests made through a Client, in place of
+// the real API, image service, and push service URLs.
+const URL = "http://groupmetest"
+
+// Sample JSON objects as returned by the API, for use in responses.
+const (
+	MemberJSON = `{
+  "id": "1000",
+  "user_id": "1234567890",
+  "nickname": "Jane",
+  "muted": false,
+  "image_url": "https://i.groupme.com/123456789"
+}`
+
+	MessageJSON = `{
+  "id": "1234567890",
+  "source_guid": "GUID",
+  "created_at": 1302623328,
+  "user_id": "1234567890",
+  "group_id": "1234567890",
+  "sender_type": "user",
+  "sender_id": "1234567890",
+  "name": "John",
+  "avatar_url": "https://i.groupme.com/123456789",
+  "text": "Hello world ☃☃",
+  "system": false,
+  "favorited_by": [
+    "101",
+    "66"
+  ],
+  "attachments": [
+    {
+      "type": "image",
+      "url": "https://i.groupme.com/123456789"
+    }
+  ]
+}`
+
+	GroupJSON = `{
+  "id": "1234567890",
+  "name": "Family",
+  "type": "private",
+  "description": "Coolest Family Ever",
+  "image_url": "https://i.groupme.com/123456789",
+  "creator_user_id": "1234567890",
+  "created_at": 1302623328,
+  "updated_at": 1302623328,
+  "members": [
+    ` + MemberJSON + `
+  ],
+  "share_url": "https://groupme.com/join_group/1234567890/SHARE_TOKEN",
+  "messages": {
+    "count": 100,
+    "last_message_id": "1234567890",
+    "last_message_created_at": 1302623328,
+    "preview": {
+      "nickname": "Jane",
+      "text": "Hello world",
+      "image_url": "https://i.groupme.com/123456789",
+      "attachments": []
+    }
+  }
+}`
+)
+
+// Envelope wraps a JSON value in the envelope used by all API responses, e.g.
+// Envelope(GroupJSON) is the response to a groups show request.
+func Envelope(response string) string {
+	return `{"meta":{"code":200},"response":` + response + `}`
+}
+
+type handler struct {
+	method  string
+	pattern string
+	status  int
+	body    string
+}
+
+// A Client is a groupme.Client that returns canned responses instead of
+// sending requests. Requests that don't match a handler receive a 404 Not
+// Found response. Like the client returned by groupme.NewClient, responses
+// with an error status code are returned with a groupme.Error.
+type Client struct {
+	mu       sync.Mutex
+	handlers []handler
+	requests []groupme.CapturedRequest
+}
+
+// NewClient creates a client with no handlers.
+func NewClient() *Client {
+	return &Client{}
+}
+
+// Handle registers the response returned for requests with the given method
+// whose path matches pattern, see path.Match for the pattern syntax. Paths
+// are relative to the API's base URL, e.g. "/groups/*/messages". If more than
+// one handler matches a request the most recently registered one is used.
+func (c *Client) Handle(method, pattern string, status int, body string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers = append(c.handlers, handler{
+		method:  method,
+		pattern: pattern,
+		status:  status,
+		body:    body,
+	})
+}
+
+// Do records the request and returns the response of the matching handler.
+func (c *Client) Do(req *http.Request) (resp *http.Response, err error) {
+	captured := groupme.CapturedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+	}
+	if req.Body != nil {
+		captured.Body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return
+		}
+	}
+
+	c.mu.Lock()
+	c.requests = append(c.requests, captured)
+	h := handler{
+		status: http.StatusNotFound,
+		body:   `{"meta":{"code":404,"errors":["not found"]},"response":null}`,
+	}
+	for i := len(c.handlers) - 1; i >= 0; i-- {
+		if c.handlers[i].method != req.Method {
+			continue
+		}
+		if ok, _ := path.Match(c.handlers[i].pattern, req.URL.Path); ok {
+			h = c.handlers[i]
+			break
+		}
+	}
+	c.mu.Unlock()
+
+	resp = &http.Response{
+		Status:        fmt.Sprintf("%d %s", h.status, http.StatusText(h.status)),
+		StatusCode:    h.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(h.body))),
+		ContentLength: int64(len(h.body)),
+		Request:       req,
+	}
+
+	// Like the real client the body of an error response is consumed by the
+	// error
+	err = groupme.CheckResponse(resp)
+	return
+}
+
+// Requests returns the requests made so far in the order they were made.
+func (c *Client) Requests() []groupme.CapturedRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]groupme.CapturedRequest(nil), c.requests...)
+}
+
+// BaseURL returns URL.
+func (c *Client) BaseURL() string { return URL }
+
+// ImageServiceURL returns URL.
+func (c *Client) ImageServiceURL() string { return URL }
+
+// PushURL returns the push service path under URL.
+func (c *Client) PushURL() string { return URL + "/faye" }
@@ -0,0 +1,66 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupmetest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jlubawy/go-groupme"
+)
+
+// TestClientErrors checks the errors returned by a Client match those of the
+// real client for the same responses.
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Status int
+		Body   string
+		Is     error
+	}{
+		{"NotFound", http.StatusNotFound, `{"meta":{"code":404,"errors":["not found"]},"response":null}`, groupme.ErrNotFound},
+		{"Unauthorized", http.StatusUnauthorized, `{"meta":{"code":401,"errors":["unauthorized"]},"response":null}`, groupme.ErrUnauthorized},
+		{"RateLimited", http.StatusTooManyRequests, ``, groupme.ErrRateLimited},
+		{"HTML", http.StatusInternalServerError, `<html>Internal Server Error</html>`, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.Status)
+				w.Write([]byte(test.Body))
+			}))
+			defer srv.Close()
+			real := groupme.NewClient(context.Background(), "TOKEN", groupme.WithBaseURL(srv.URL))
+
+			fake := NewClient()
+			fake.Handle(http.MethodGet, "/groups/*", test.Status, test.Body)
+
+			var errs []error
+			for _, c := range []groupme.Client{real, fake} {
+				_, err := groupme.NewGroupsService(c).Show(context.Background(), "1", nil)
+				if test.Is != nil && !errors.Is(err, test.Is) {
+					t.Errorf("%T: expected error to be %v but got %v", c, test.Is, err)
+				}
+				errs = append(errs, err)
+			}
+			if !reflect.DeepEqual(errs[0], errs[1]) {
+				t.Errorf("expected the fake's error %#v to match the real one %#v", errs[1], errs[0])
+			}
+		})
+	}
+}
+
+func TestClientUnhandled(t *testing.T) {
+	c := NewClient()
+	_, err := groupme.NewGroupsService(c).Show(context.Background(), "1", nil)
+	if !errors.Is(err, groupme.ErrNotFound) {
+		t.Errorf("expected unhandled requests to be not found but got %v", err)
+	}
+}
@@ -0,0 +1,406 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PushURL is the URL of the push service, a Faye server implementing the
+// Bayeux protocol. It is accessed using the long-polling transport, which
+// unlike the websocket transport only requires an HTTP client.
+const PushURL = "https://push.groupme.com/faye"
+
+// pushRequestTimeout is the time allowed for a push service request, in
+// addition to the advised timeout for long polls.
+const pushRequestTimeout = 30 * time.Second
+
+// A pushRequestKey marks the context of requests made by a PushService.
+type pushRequestKey struct{}
+
+// isPushRequest reports whether a request with the given context was made by a
+// PushService.
+func isPushRequest(ctx context.Context) bool {
+	push, _ := ctx.Value(pushRequestKey{}).(bool)
+	return push
+}
+
+// PushService implements all the methods needed to receive messages from the
+// push service as they are created.
+type PushService interface {
+	Subscribe(ctx context.Context, userID string, groupIDs ...string) (messages <-chan Message, err error)
+	Err() (err error)
+	Close() (err error)
+}
+
+type pushService struct {
+	client      Client
+	accessToken string
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+
+	// The following are only used by the connection goroutine once started
+	clientID string
+	channels []string
+	nextID   int
+	advice   bayeuxAdvice
+}
+
+// NewPushService creates a push service. The access token is required in
+// addition to the client since it must be included in the subscription
+// requests themselves.
+func NewPushService(client Client, accessToken string) PushService {
+	return &pushService{
+		client:      client,
+		accessToken: accessToken,
+	}
+}
+
+type bayeuxMessage struct {
+	Channel                  string          `json:"channel"`
+	ID                       string          `json:"id,omitempty"`
+	ClientID                 string          `json:"clientId,omitempty"`
+	Version                  string          `json:"version,omitempty"`
+	SupportedConnectionTypes []string        `json:"supportedConnectionTypes,omitempty"`
+	ConnectionType           string          `json:"connectionType,omitempty"`
+	Subscription             string          `json:"subscription,omitempty"`
+	Successful               bool            `json:"successful,omitempty"`
+	Error                    string          `json:"error,omitempty"`
+	Advice                   *bayeuxAdvice   `json:"advice,omitempty"`
+	Ext                      *bayeuxExt      `json:"ext,omitempty"`
+	Data                     json.RawMessage `json:"data,omitempty"`
+}
+
+type bayeuxAdvice struct {
+	Reconnect string `json:"reconnect,omitempty"`
+	Interval  int    `json:"interval"` // milliseconds
+	Timeout   int    `json:"timeout"`  // milliseconds
+}
+
+type bayeuxExt struct {
+	AccessToken string `json:"access_token"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// Subscribe connects to the push service and subscribes to the channels of the
+// given user and groups. The user channel receives the messages of all the
+// user's groups and direct messages, so group channels are only needed for
+// groups the user is not a member of. Messages are delivered on the returned
+// channel, which is closed once the context is done, the service is closed, or
+// the connection fails permanently, see Err. The service may then be
+// subscribed again.
+//
+// If the connection is lost it is reestablished following the server's advice.
+func (s *pushService) Subscribe(ctx context.Context, userID string, groupIDs ...string) (messages <-chan Message, err error) {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		err = fmt.Errorf("PushService.Subscribe: already subscribed")
+		return
+	}
+
+	// Mark the service as subscribed before the handshake, which is done
+	// without holding the lock so Err and Close aren't blocked by it
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	s.cancel, s.done, s.err = cancel, done, nil
+	s.mu.Unlock()
+
+	s.channels = []string{"/user/" + userID}
+	for _, groupID := range groupIDs {
+		s.channels = append(s.channels, "/group/"+groupID)
+	}
+
+	err = s.handshake(ctx)
+	if err != nil {
+		cancel()
+		s.unsubscribed(done)
+		return
+	}
+
+	ch := make(chan Message)
+	go s.run(ctx, ch, done)
+
+	messages = ch
+	return
+}
+
+// Err returns the error that caused the connection to stop, if any. While
+// subscribed it returns the most recent error connecting to the push service,
+// if the connection hasn't been reestablished since.
+func (s *pushService) Err() (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close disconnects from the push service and closes the messages channel.
+func (s *pushService) Close() (err error) {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	return
+}
+
+// run long-polls the push service until the context is done, delivering any
+// messages received. The service can be subscribed again once it returns.
+func (s *pushService) run(ctx context.Context, messages chan<- Message, done chan struct{}) {
+	defer s.unsubscribed(done)
+	defer close(messages)
+	defer s.disconnect()
+
+	// Messages may be received on both the user and group channels
+	seen := make(map[string]bool)
+
+	for attempt := 0; ; {
+		resps, err := s.send(ctx, bayeuxMessage{
+			Channel:        "/meta/connect",
+			ClientID:       s.clientID,
+			ConnectionType: "long-polling",
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.setErr(fmt.Errorf("PushService: connect failed: %v", err))
+		} else {
+			s.setErr(nil)
+		}
+
+		reconnect := "retry"
+		for _, m := range resps {
+			if m.Channel == "/meta/connect" {
+				if !m.Successful {
+					reconnect = "handshake"
+				}
+				if m.Advice != nil {
+					s.advice = *m.Advice
+					if m.Advice.Reconnect != "" {
+						reconnect = m.Advice.Reconnect
+					}
+				}
+				continue
+			}
+
+			message, ok := decodePushMessage(m)
+			if !ok || seen[message.ID] {
+				continue
+			}
+			if len(seen) > 1000 {
+				seen = make(map[string]bool)
+			}
+			seen[message.ID] = true
+
+			select {
+			case messages <- message:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		switch {
+		case err != nil:
+			reconnect = "handshake"
+			if !sleep(ctx, backoff(attempt)) {
+				return
+			}
+			attempt++
+		case reconnect == "none":
+			s.setErr(fmt.Errorf("PushService: server advised not to reconnect"))
+			return
+		default:
+			attempt = 0
+			if !sleep(ctx, time.Duration(s.advice.Interval)*time.Millisecond) {
+				return
+			}
+		}
+
+		for reconnect == "handshake" {
+			err = s.handshake(ctx)
+			if err == nil {
+				break
+			}
+			if ctx.Err() == nil {
+				s.setErr(err)
+			}
+			if !sleep(ctx, backoff(attempt)) {
+				return
+			}
+			attempt++
+		}
+	}
+}
+
+// handshake gets a new client ID and subscribes it to the channels.
+func (s *pushService) handshake(ctx context.Context) (err error) {
+	var resps []bayeuxMessage
+	resps, err = s.send(ctx, bayeuxMessage{
+		Channel:                  "/meta/handshake",
+		Version:                  "1.0",
+		SupportedConnectionTypes: []string{"long-polling"},
+	})
+	if err != nil {
+		return
+	}
+	if len(resps) == 0 || !resps[0].Successful {
+		err = fmt.Errorf("PushService: handshake failed: %s", bayeuxError(resps))
+		return
+	}
+	s.clientID = resps[0].ClientID
+	if resps[0].Advice != nil {
+		s.advice = *resps[0].Advice
+	}
+
+	var reqs []bayeuxMessage
+	for _, channel := range s.channels {
+		reqs = append(reqs, bayeuxMessage{
+			Channel:      "/meta/subscribe",
+			ClientID:     s.clientID,
+			Subscription: channel,
+			Ext: &bayeuxExt{
+				AccessToken: s.accessToken,
+				Timestamp:   time.Now().Unix(),
+			},
+		})
+	}
+	resps, err = s.send(ctx, reqs...)
+	if err != nil {
+		return
+	}
+	for _, m := range resps {
+		if m.Channel == "/meta/subscribe" && !m.Successful {
+			err = fmt.Errorf("PushService: subscribing to '%s' failed: %s", m.Subscription, m.Error)
+			return
+		}
+	}
+	return
+}
+
+// disconnect tells the server the client is going away. Any error is ignored
+// since the server will eventually expire the client anyway.
+func (s *pushService) disconnect() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.send(ctx, bayeuxMessage{
+		Channel:  "/meta/disconnect",
+		ClientID: s.clientID,
+	})
+}
+
+func (s *pushService) send(ctx context.Context, msgs ...bayeuxMessage) (resps []bayeuxMessage, err error) {
+	timeout := pushRequestTimeout
+	for i := range msgs {
+		s.nextID++
+		msgs[i].ID = strconv.Itoa(s.nextID)
+
+		// The server holds connect requests open for up to the advised
+		// timeout waiting for messages
+		if msgs[i].Channel == "/meta/connect" {
+			timeout += time.Duration(s.advice.Timeout) * time.Millisecond
+		}
+	}
+
+	// Mark the request so the client doesn't apply its own timeout or rate
+	// limit, see WithTimeout
+	ctx, cancel := context.WithTimeout(context.WithValue(ctx, pushRequestKey{}, true), timeout)
+	defer cancel()
+
+	reqBuf := &bytes.Buffer{}
+	err = json.NewEncoder(reqBuf).Encode(msgs)
+	if err != nil {
+		return
+	}
+
+	var req *http.Request
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, pushURL(s.client), reqBuf)
+	if err != nil {
+		return
+	}
+
+	var resp *http.Response
+	resp, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&resps)
+	return
+}
+
+// unsubscribed clears the subscription so the service can be subscribed again,
+// and then closes done.
+func (s *pushService) unsubscribed(done chan struct{}) {
+	s.mu.Lock()
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+	close(done)
+}
+
+func (s *pushService) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// decodePushMessage decodes the message created event from a data message, if
+// it is one.
+func decodePushMessage(m bayeuxMessage) (message Message, ok bool) {
+	if len(m.Data) == 0 {
+		return
+	}
+
+	var data struct {
+		Type    string  `json:"type"`
+		Subject Message `json:"subject"`
+	}
+	if json.Unmarshal(m.Data, &data) != nil {
+		return
+	}
+	switch data.Type {
+	case "line.create", "direct_message.create":
+		return data.Subject, true
+	}
+	return
+}
+
+func bayeuxError(resps []bayeuxMessage) string {
+	if len(resps) == 0 || resps[0].Error == "" {
+		return "unknown error"
+	}
+	return resps[0].Error
+}
+
+// sleep waits for the given duration, returning false if the context is done
+// first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
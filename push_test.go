@@ -0,0 +1,194 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A fayeServer is a push server that holds each connect request open until a
+// message is published or the hold time passes, like the real one does.
+type fayeServer struct {
+	*httptest.Server
+
+	hold     time.Duration
+	messages chan string // data of messages to deliver on /user/1
+
+	mu          sync.Mutex
+	failConnect bool
+	handshakes  int
+}
+
+func newFayeServer(t *testing.T, hold time.Duration) *fayeServer {
+	s := &fayeServer{
+		hold:     hold,
+		messages: make(chan string, 1),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []bayeuxMessage
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Errorf("error decoding push request: %v", err)
+		}
+
+		var resps []bayeuxMessage
+		for _, req := range reqs {
+			resp := bayeuxMessage{Channel: req.Channel, ID: req.ID, ClientID: "CLIENT", Successful: true}
+			switch req.Channel {
+			case "/meta/handshake":
+				s.mu.Lock()
+				s.handshakes++
+				s.mu.Unlock()
+				resp.Advice = &bayeuxAdvice{Reconnect: "retry", Timeout: int(hold / time.Millisecond)}
+			case "/meta/subscribe":
+				resp.Subscription = req.Subscription
+				if req.Ext == nil || req.Ext.AccessToken != testAccessToken {
+					resp.Successful = false
+					resp.Error = "401::Unauthorized"
+				}
+			case "/meta/connect":
+				s.mu.Lock()
+				fail := s.failConnect
+				s.mu.Unlock()
+				if fail {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				timer := time.NewTimer(s.hold)
+				select {
+				case data := <-s.messages:
+					resps = append(resps, bayeuxMessage{Channel: "/user/1", Data: json.RawMessage(data)})
+				case <-timer.C:
+				case <-r.Context().Done():
+				}
+				timer.Stop()
+			}
+			resps = append(resps, resp)
+		}
+		json.NewEncoder(w).Encode(resps)
+	}))
+	return s
+}
+
+func (s *fayeServer) Client(options ...ClientOption) Client {
+	return NewClient(context.Background(), testAccessToken, append([]ClientOption{WithPushURL(s.URL)}, options...)...)
+}
+
+func (s *fayeServer) SetFailConnect(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failConnect = fail
+}
+
+func (s *fayeServer) Handshakes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handshakes
+}
+
+func receiveMessage(t *testing.T, messages <-chan Message) (message Message) {
+	select {
+	case message = <-messages:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for a message")
+	}
+	return
+}
+
+func TestPushServiceSubscribe(t *testing.T) {
+	srv := newFayeServer(t, 200*time.Millisecond)
+	defer srv.Close()
+
+	// Long polls outlast the client timeout and would exhaust the rate limit
+	// if either applied to them
+	s := NewPushService(srv.Client(
+		WithTimeout(50*time.Millisecond),
+		WithRateLimiter(NewRateLimiter(0.001, 1)),
+	), testAccessToken)
+
+	for i := 0; i < 2; i++ {
+		messages, err := s.Subscribe(context.Background(), "1")
+		if err != nil {
+			t.Fatalf("unexpected error subscribing: %v", err)
+		}
+
+		time.Sleep(150 * time.Millisecond)
+		srv.messages <- `{"type":"line.create","subject":{"id":"1","text":"Hello"}}`
+		if message := receiveMessage(t, messages); message.ID != "1" || message.Text != "Hello" {
+			t.Errorf("unexpected message: %+v", message)
+		}
+
+		// Wait for a long poll to time out without messages
+		time.Sleep(300 * time.Millisecond)
+		srv.messages <- `{"type":"line.create","subject":{"id":"2","text":"World"}}`
+		if message := receiveMessage(t, messages); message.ID != "2" {
+			t.Errorf("unexpected message: %+v", message)
+		}
+		if err := s.Err(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		// The service can be subscribed again once closed
+		if err := s.Close(); err != nil {
+			t.Fatalf("unexpected error closing: %v", err)
+		}
+		if _, ok := <-messages; ok {
+			t.Errorf("expected messages channel to be closed")
+		}
+	}
+
+	if n := srv.Handshakes(); n != 2 {
+		t.Errorf("expected 2 handshakes but got %d", n)
+	}
+}
+
+func TestPushServiceSubscribeUnauthorized(t *testing.T) {
+	srv := newFayeServer(t, 200*time.Millisecond)
+	defer srv.Close()
+
+	s := NewPushService(srv.Client(), "WRONG")
+	if _, err := s.Subscribe(context.Background(), "1"); err == nil {
+		t.Errorf("expected error subscribing with the wrong access token")
+	}
+	if _, err := s.Subscribe(context.Background(), "1"); err == nil {
+		t.Errorf("expected error subscribing again with the wrong access token")
+	}
+}
+
+func TestPushServiceErr(t *testing.T) {
+	srv := newFayeServer(t, 200*time.Millisecond)
+	defer srv.Close()
+
+	s := NewPushService(srv.Client(), testAccessToken)
+	messages, err := s.Subscribe(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("unexpected error subscribing: %v", err)
+	}
+	defer s.Close()
+
+	// Connection errors are reported while the service reconnects
+	srv.SetFailConnect(true)
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Err() == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for a connection error")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// And cleared once it reconnects
+	srv.SetFailConnect(false)
+	srv.messages <- `{"type":"line.create","subject":{"id":"1"}}`
+	receiveMessage(t, messages)
+	if err := s.Err(); err != nil {
+		t.Errorf("expected error to be cleared after reconnecting but got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRateLimit is the number of requests per second allowed by the
+	// limiter returned by NewDefaultRateLimiter. GroupMe does not publish
+	// exact limits, so it is kept conservative.
+	DefaultRateLimit = 2
+
+	// DefaultRateLimitBurst is the number of requests that may be made at once
+	// by the limiter returned by NewDefaultRateLimiter.
+	DefaultRateLimitBurst = 10
+)
+
+// A RateLimiter limits the rate at which a client makes requests. Wait blocks
+// until a request may be made or the context is done. A *rate.Limiter from
+// golang.org/x/time/rate implements it.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// NewRateLimiter creates a token bucket rate limiter allowing requests at the
+// given rate per second, with bursts of up to burst requests. It panics if the
+// rate isn't positive, like time.NewTicker does for a non-positive interval. A
+// burst less than one is treated as one. Rates above one request per
+// nanosecond are treated as one per nanosecond.
+func NewRateLimiter(perSecond float64, burst int) RateLimiter {
+	if !(perSecond > 0) {
+		panic("groupme: non-positive rate for NewRateLimiter")
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	// A zero interval would make the number of tokens added undefined
+	interval := time.Duration(float64(time.Second) / perSecond)
+	if interval < 1 {
+		interval = 1
+	}
+	return &tokenBucket{
+		interval: interval,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// NewDefaultRateLimiter creates a rate limiter using DefaultRateLimit and
+// DefaultRateLimitBurst.
+func NewDefaultRateLimiter() RateLimiter {
+	return NewRateLimiter(DefaultRateLimit, DefaultRateLimitBurst)
+}
+
+type tokenBucket struct {
+	mu       sync.Mutex
+	interval time.Duration // time taken to add a token
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// Wait takes a token from the bucket, waiting for one to be added if the bucket
+// is empty. If the context is done first the token is returned.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += float64(now.Sub(b.last)) / float64(b.interval)
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	b.tokens--
+	wait := time.Duration(-b.tokens * float64(b.interval))
+	b.mu.Unlock()
+
+	if sleep(ctx, wait) {
+		return nil
+	}
+
+	b.mu.Lock()
+	b.tokens++
+	b.mu.Unlock()
+	return ctx.Err()
+}
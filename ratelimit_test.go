@@ -0,0 +1,80 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(20, 2)
+
+	// The burst is allowed at once, then requests are spaced by the interval
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if d := time.Since(start); d < 90*time.Millisecond || d > time.Second {
+		t.Errorf("expected 4 requests with a burst of 2 at 20 per second to take about 100ms but took %v", d)
+	}
+}
+
+func TestRateLimiterContextDone(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected context.DeadlineExceeded but got %v", err)
+	}
+
+	// The token taken by the cancelled wait is returned, so the next request
+	// waits for the rest of the interval rather than two
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := time.Since(start); d > 1500*time.Millisecond {
+		t.Errorf("expected to wait less than the interval but waited %v", d)
+	}
+}
+
+func TestRateLimiterHighRate(t *testing.T) {
+	for _, perSecond := range []float64{1e9, 1e12, math.MaxFloat64, math.Inf(1)} {
+		l := NewRateLimiter(perSecond, 1)
+		if b := l.(*tokenBucket); b.interval < 1 {
+			t.Errorf("%g per second: expected a positive interval but got %v", perSecond, b.interval)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		for i := 0; i < 1000; i++ {
+			if err := l.Wait(ctx); err != nil {
+				t.Fatalf("%g per second: unexpected error: %v", perSecond, err)
+			}
+		}
+		cancel()
+	}
+}
+
+func TestRateLimiterInvalidRate(t *testing.T) {
+	for _, perSecond := range []float64{0, -1, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%g per second: expected panic", perSecond)
+				}
+			}()
+			NewRateLimiter(perSecond, 1)
+		}()
+	}
+}
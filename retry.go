@@ -0,0 +1,141 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+)
+
+// A RetryClient is a Client that retries requests that fail with a 429 Too Many
+// Requests or 5xx status code. The delay between attempts is taken from the
+// 'Retry-After' header when present, otherwise an exponential backoff with
+// jitter is used. Retrying stops once the request's context is done.
+type RetryClient struct {
+	clientWrapper
+	maxRetries int
+
+	// RetryNonIdempotent enables retrying requests that are not idempotent, such
+	// as POST requests. It is false by default since a retried POST may, for
+	// example, post the same message twice.
+	RetryNonIdempotent bool
+}
+
+// NewRetryClient creates a client that retries requests made with c up to
+// maxRetries times.
+func NewRetryClient(c Client, maxRetries int) *RetryClient {
+	return &RetryClient{
+		clientWrapper: clientWrapper{client: c},
+		maxRetries:    maxRetries,
+	}
+}
+
+// Do makes an API request, retrying it if necessary.
+func (c *RetryClient) Do(req *http.Request) (resp *http.Response, err error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			r = req.Clone(ctx)
+			if req.GetBody != nil {
+				r.Body, err = req.GetBody()
+				if err != nil {
+					return
+				}
+			}
+		}
+
+		resp, err = c.client.Do(r)
+		if attempt >= c.maxRetries || !c.shouldRetry(req, resp) {
+			return
+		}
+		resp.Body.Close()
+
+		delay, ok := retryAfter(resp)
+		if !ok {
+			delay = backoff(attempt)
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			// Don't return the response being retried, its body is closed
+			t.Stop()
+			resp, err = nil, ctx.Err()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// WithContext returns a new retry client wrapping the wrapped client bound to
+// the given context, if it supports binding one, see ContextBinder.
+func (c *RetryClient) WithContext(ctx context.Context) Client {
+	retry := *c
+	retry.clientWrapper = c.withContext(ctx)
+	return &retry
+}
+
+func (c *RetryClient) shouldRetry(req *http.Request, resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return false
+	}
+
+	// A request with a body can only be retried if the body can be recreated
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return c.RetryNonIdempotent
+	}
+}
+
+// retryAfter returns the delay requested by the response's 'Retry-After'
+// header, which may be either a number of seconds or an HTTP date.
+func retryAfter(resp *http.Response) (delay time.Duration, ok bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return
+	}
+	if sec, err := strconv.Atoi(v); err == nil && sec >= 0 {
+		return time.Duration(sec) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		delay = time.Until(t)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return
+}
+
+// backoff returns a random delay of up to retryBaseDelay doubled for each
+// attempt, capped at retryMaxDelay.
+func backoff(attempt int) time.Duration {
+	d := retryMaxDelay
+	if attempt < 16 {
+		if exp := retryBaseDelay << uint(attempt); exp < retryMaxDelay {
+			d = exp
+		}
+	}
+	return time.Duration(rand.Int63n(int64(d)) + 1)
+}
@@ -0,0 +1,209 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newRetryTestServer creates a server that responds to successive requests with
+// the given statuses, repeating the last one. Error responses ask to be retried
+// after retryAfter.
+func newRetryTestServer(retryAfter string, statuses ...int) (srv *httptest.Server, requests func() int) {
+	var (
+		mu sync.Mutex
+		n  int
+	)
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		status := statuses[len(statuses)-1]
+		if n < len(statuses) {
+			status = statuses[n]
+		}
+		n++
+		mu.Unlock()
+
+		if status >= 400 && retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(`{"meta":{"code":` + strconv.Itoa(status) + `},"response":{}}`))
+	}))
+	requests = func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return n
+	}
+	return
+}
+
+func TestRetryClientStatuses(t *testing.T) {
+	tests := []struct {
+		Status   int
+		Requests int
+	}{
+		{http.StatusTooManyRequests, 3},
+		{http.StatusInternalServerError, 3},
+		{http.StatusBadGateway, 3},
+		{http.StatusServiceUnavailable, 3},
+		{http.StatusBadRequest, 1},
+		{http.StatusUnauthorized, 1},
+		{http.StatusNotFound, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(strconv.Itoa(test.Status), func(t *testing.T) {
+			srv, requests := newRetryTestServer("0", test.Status, test.Status, http.StatusOK)
+			defer srv.Close()
+			c := NewRetryClient(NewClient(context.Background(), testAccessToken, WithBaseURL(srv.URL)), 5)
+
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+"/groups", nil)
+			resp, err := c.Do(req)
+			if test.Requests > 1 && err != nil {
+				t.Errorf("expected retries to succeed but got %v", err)
+			}
+			if test.Requests == 1 && err == nil {
+				t.Errorf("expected error without retrying")
+			}
+			if resp != nil {
+				resp.Body.Close()
+			}
+			if n := requests(); n != test.Requests {
+				t.Errorf("expected %d requests but got %d", test.Requests, n)
+			}
+		})
+	}
+}
+
+func TestRetryClientMaxRetries(t *testing.T) {
+	srv, requests := newRetryTestServer("0", http.StatusServiceUnavailable)
+	defer srv.Close()
+	c := NewRetryClient(NewClient(context.Background(), testAccessToken, WithBaseURL(srv.URL)), 2)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/groups", nil)
+	resp, err := c.Do(req)
+	var apiErr Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 error but got %v", err)
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if n := requests(); n != 3 {
+		t.Errorf("expected 3 requests but got %d", n)
+	}
+}
+
+func TestRetryClientNonIdempotent(t *testing.T) {
+	for _, retry := range []bool{false, true} {
+		t.Run(strconv.FormatBool(retry), func(t *testing.T) {
+			srv, requests := newRetryTestServer("0", http.StatusServiceUnavailable, http.StatusOK)
+			defer srv.Close()
+			c := NewRetryClient(NewClient(context.Background(), testAccessToken, WithBaseURL(srv.URL)), 2)
+			c.RetryNonIdempotent = retry
+
+			req, _ := http.NewRequest(http.MethodPost, srv.URL+"/groups/1/messages", strings.NewReader(`{}`))
+			resp, err := c.Do(req)
+			if resp != nil {
+				resp.Body.Close()
+			}
+
+			expected := 1
+			if retry {
+				expected = 2
+			}
+			if n := requests(); n != expected {
+				t.Errorf("expected %d requests but got %d", expected, n)
+			}
+			if retry != (err == nil) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryClientContextDone(t *testing.T) {
+	srv, _ := newRetryTestServer("60", http.StatusServiceUnavailable)
+	defer srv.Close()
+	c := NewRetryClient(NewClient(context.Background(), testAccessToken, WithBaseURL(srv.URL)), 5)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/groups", nil)
+
+	start := time.Now()
+	resp, err := c.Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded but got %v", err)
+	}
+	if resp != nil {
+		t.Errorf("expected no response")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("expected to stop waiting when the context was done, took %v", d)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	past := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	tests := []struct {
+		Header string
+		Min    time.Duration
+		Max    time.Duration
+		OK     bool
+	}{
+		{"", 0, 0, false},
+		{"120", 120 * time.Second, 120 * time.Second, true},
+		{"0", 0, 0, true},
+		{"-1", 0, 0, false},
+		{"soon", 0, 0, false},
+		{future, 59 * time.Minute, time.Hour, true},
+		{past, 0, 0, true},
+	}
+
+	for _, test := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if test.Header != "" {
+			resp.Header.Set("Retry-After", test.Header)
+		}
+		delay, ok := retryAfter(resp)
+		if ok != test.OK || delay < test.Min || delay > test.Max {
+			t.Errorf("Retry-After '%s': expected %v between %v and %v but got %v %v", test.Header, test.OK, test.Min, test.Max, ok, delay)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 20; attempt++ {
+		max := retryMaxDelay
+		if attempt < 16 && retryBaseDelay<<uint(attempt) < retryMaxDelay {
+			max = retryBaseDelay << uint(attempt)
+		}
+
+		// Full jitter picks any delay up to the maximum
+		var sawLow, sawHigh bool
+		for i := 0; i < 1000; i++ {
+			d := backoff(attempt)
+			if d <= 0 || d > max {
+				t.Fatalf("attempt %d: delay %v out of range (0, %v]", attempt, d, max)
+			}
+			sawLow = sawLow || d < max/4
+			sawHigh = sawHigh || d > max*3/4
+		}
+		if !sawLow || !sawHigh {
+			t.Errorf("attempt %d: expected delays spread across (0, %v]", attempt, max)
+		}
+	}
+}
@@ -6,16 +6,22 @@ package groupme
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Attachment struct {
 	Type string `json:"type"`
 
-	// Image attachment fields.
-	URL string `json:"url,omitempty"`
+	// Image and video attachment fields.
+	URL        string `json:"url,omitempty"`
+	PreviewURL string `json:"preview_url,omitempty"`
 
 	// Location attachment fields.
 	Lat  string `json:"lat,omitempty"`
@@ -26,12 +32,22 @@ type Attachment struct {
 	Loci    [][]int  `json:"loci,omitempty"`
 	UserIDs []string `json:"user_ids,omitempty"`
 
-	// Split attachment fields.
+	// Split attachment fields. The token identifies a bill split, see
+	// NewSplitAttachment.
 	Token string `json:"token,omitempty"`
 
-	// Emoji attachment fields.
+	// Emoji attachment fields. Each occurrence of the placeholder character in
+	// the message text is replaced by the emoji at the same index of the
+	// charmap, see EmojiPlaceholders.
 	Placeholder string    `json:"placeholder,omitempty"`
 	Charmap     []Charmap `json:"charmap,omitempty"`
+
+	// Reply attachment fields.
+	ReplyID     string `json:"reply_id,omitempty"`
+	BaseReplyID string `json:"base_reply_id,omitempty"`
+
+	// File attachment fields.
+	FileID string `json:"file_id,omitempty"`
 }
 
 func (a Attachment) IsTypeImage() bool    { return a.Type == "image" }
@@ -39,9 +55,395 @@ func (a Attachment) IsTypeLocation() bool { return a.Type == "location" }
 func (a Attachment) IsTypeMentions() bool { return a.Type == "mentions" }
 func (a Attachment) IsTypeSplit() bool    { return a.Type == "split" }
 func (a Attachment) IsTypeEmoji() bool    { return a.Type == "emoji" }
+func (a Attachment) IsTypeReply() bool    { return a.Type == "reply" }
+func (a Attachment) IsTypeFile() bool     { return a.Type == "file" }
+func (a Attachment) IsTypeVideo() bool    { return a.Type == "video" }
+
+// MarshalJSON encodes only the fields relevant to the attachment's type, so for
+// example an image attachment never includes mentions fields. Attachments of
+// unknown types encode all non-empty fields.
+func (a Attachment) MarshalJSON() ([]byte, error) {
+	type attachment Attachment // prevent recursion
+
+	v := attachment{Type: a.Type}
+	switch a.Type {
+	case "image":
+		v.URL = a.URL
+	case "location":
+		v.Lat, v.Lng, v.Name = a.Lat, a.Lng, a.Name
+	case "mentions":
+		v.Loci, v.UserIDs = a.Loci, a.UserIDs
+	case "split":
+		v.Token = a.Token
+	case "emoji":
+		v.Placeholder, v.Charmap = a.Placeholder, a.Charmap
+	case "reply":
+		v.ReplyID, v.BaseReplyID = a.ReplyID, a.BaseReplyID
+	case "file":
+		v.FileID = a.FileID
+	case "video":
+		v.URL, v.PreviewURL = a.URL, a.PreviewURL
+	default:
+		v = attachment(a)
+	}
+	return json.Marshal(v)
+}
+
+// NewImageAttachment creates an image attachment. The URL must be hosted by the
+// image service, see ImageService.Upload.
+func NewImageAttachment(url string) Attachment {
+	return Attachment{
+		Type: "image",
+		URL:  url,
+	}
+}
+
+// NewLocationAttachment creates a location attachment. A zero Attachment is
+// returned if the latitude or longitude is out of range, use
+// NewLocationAttachmentErr to get the reason.
+func NewLocationAttachment(name string, lat, lng float64) Attachment {
+	a, _ := NewLocationAttachmentErr(name, lat, lng)
+	return a
+}
+
+// NewLocationAttachmentErr creates a location attachment. An error is returned
+// if the latitude is not within -90 to 90 or the longitude is not within -180
+// to 180.
+func NewLocationAttachmentErr(name string, lat, lng float64) (a Attachment, err error) {
+	if lat < -90 || lat > 90 {
+		err = fmt.Errorf("NewLocationAttachment: latitude must be between -90 and 90")
+		return
+	}
+	if lng < -180 || lng > 180 {
+		err = fmt.Errorf("NewLocationAttachment: longitude must be between -180 and 180")
+		return
+	}
+	a = Attachment{
+		Type: "location",
+		Lat:  strconv.FormatFloat(lat, 'f', -1, 64),
+		Lng:  strconv.FormatFloat(lng, 'f', -1, 64),
+		Name: name,
+	}
+	return
+}
+
+// NewSplitAttachment creates a split attachment, which shares a bill split with
+// the group. Splits are created by the GroupMe apps using the Split service,
+// which is not part of the public API, so the token can only be obtained from
+// an existing split attachment, e.g. one received in another message.
+func NewSplitAttachment(token string) Attachment {
+	return Attachment{
+		Type:  "split",
+		Token: token,
+	}
+}
+
+// NewReplyAttachment creates a reply attachment. The reply ID is the ID of the
+// message being replied to, and the base reply ID is the ID of the first
+// message in the reply chain, which is the same as the reply ID unless replying
+// to a reply.
+func NewReplyAttachment(replyID, baseReplyID string) Attachment {
+	return Attachment{
+		Type:        "reply",
+		ReplyID:     replyID,
+		BaseReplyID: baseReplyID,
+	}
+}
+
+// NewFileAttachment creates a file attachment for a file that has already been
+// uploaded to the file service.
+func NewFileAttachment(fileID string) Attachment {
+	return Attachment{
+		Type:   "file",
+		FileID: fileID,
+	}
+}
+
+// NewVideoAttachment creates a video attachment with the URL of the video and
+// of its preview image.
+func NewVideoAttachment(url, previewURL string) Attachment {
+	return Attachment{
+		Type:       "video",
+		URL:        url,
+		PreviewURL: previewURL,
+	}
+}
+
+// Validate checks that the fields required by the attachment's type are set and
+// valid. Attachments of unknown types are not checked beyond having a type.
+func (a Attachment) Validate() error {
+	switch a.Type {
+	case "":
+		return errors.New("attachment type is required")
+	case "image":
+		if a.URL == "" {
+			return errors.New("image attachment URL is required")
+		}
+	case "location":
+		lat, err := strconv.ParseFloat(a.Lat, 64)
+		if err != nil || lat < -90 || lat > 90 {
+			return fmt.Errorf("location attachment latitude '%s' is invalid", a.Lat)
+		}
+		lng, err := strconv.ParseFloat(a.Lng, 64)
+		if err != nil || lng < -180 || lng > 180 {
+			return fmt.Errorf("location attachment longitude '%s' is invalid", a.Lng)
+		}
+	case "mentions":
+		if len(a.Loci) != len(a.UserIDs) {
+			return fmt.Errorf("mentions attachment has %d loci but %d user IDs", len(a.Loci), len(a.UserIDs))
+		}
+		for i, loci := range a.Loci {
+			if len(loci) != 2 || loci[0] < 0 || loci[1] < 0 {
+				return fmt.Errorf("mentions attachment loci %d must be a non-negative start and length", i)
+			}
+		}
+	case "split":
+		if a.Token == "" {
+			return errors.New("split attachment token is required")
+		}
+	case "emoji":
+		if a.Placeholder == "" {
+			return errors.New("emoji attachment placeholder is required")
+		}
+		for i, c := range a.Charmap {
+			if len(c) != 2 {
+				return fmt.Errorf("emoji attachment charmap %d must be a pack ID and offset", i)
+			}
+		}
+	case "reply":
+		if a.ReplyID == "" || a.BaseReplyID == "" {
+			return errors.New("reply attachment reply ID and base reply ID are required")
+		}
+	case "file":
+		if a.FileID == "" {
+			return errors.New("file attachment file ID is required")
+		}
+	case "video":
+		if a.URL == "" {
+			return errors.New("video attachment URL is required")
+		}
+	}
+	return nil
+}
+
+// An EmojiRef identifies an emoji by the ID of the emoji pack containing it and
+// its offset within the pack.
+type EmojiRef struct {
+	PackID int
+	Offset int
+}
+
+// EmojiPlaceholders returns the emoji referenced by an emoji attachment's
+// charmap. The emoji at index i replaces the i-th occurrence of the
+// attachment's placeholder in the message text, so for example a message with
+// text "hi \ufffd\ufffd" and placeholder "\ufffd" contains two emoji. Malformed
+// charmap entries are returned as a zero EmojiRef so indices still line up
+// with the placeholders. Nil is returned for other attachment types.
+func (a Attachment) EmojiPlaceholders() (refs []EmojiRef) {
+	if !a.IsTypeEmoji() {
+		return
+	}
+	refs = make([]EmojiRef, len(a.Charmap))
+	for i, c := range a.Charmap {
+		if len(c) == 2 {
+			refs[i] = EmojiRef{PackID: int(c[0]), Offset: int(c[1])}
+		}
+	}
+	return
+}
+
+// ErrUnknownAttachmentType is returned by Attachment.Decode for attachments of
+// an unknown type.
+var ErrUnknownAttachmentType = errors.New("Attachment.Decode: unknown attachment type")
+
+type ImageAttachment struct {
+	URL string
+}
+
+type LocationAttachment struct {
+	Name string
+	Lat  float64
+	Lng  float64
+}
+
+type MentionsAttachment struct {
+	Mentions []Mention
+}
+
+type SplitAttachment struct {
+	Token string
+}
+
+type EmojiAttachment struct {
+	Placeholder string
+	Charmap     []Charmap
+}
+
+type ReplyAttachment struct {
+	ReplyID     string
+	BaseReplyID string
+}
+
+type FileAttachment struct {
+	FileID string
+}
+
+type VideoAttachment struct {
+	URL        string
+	PreviewURL string
+}
+
+// Decode returns the attachment as one of the typed attachments, e.g. an
+// ImageAttachment for an image attachment. ErrUnknownAttachmentType is returned
+// if the attachment type isn't known.
+func (a Attachment) Decode() (v interface{}, err error) {
+	switch a.Type {
+	case "image":
+		v = ImageAttachment{URL: a.URL}
+	case "location":
+		l := LocationAttachment{Name: a.Name}
+		l.Lat, err = strconv.ParseFloat(a.Lat, 64)
+		if err != nil {
+			return
+		}
+		l.Lng, err = strconv.ParseFloat(a.Lng, 64)
+		if err != nil {
+			return
+		}
+		v = l
+	case "mentions":
+		if len(a.Loci) != len(a.UserIDs) {
+			err = fmt.Errorf("Attachment.Decode: mentions have %d loci but %d user IDs", len(a.Loci), len(a.UserIDs))
+			return
+		}
+		var m MentionsAttachment
+		for i, loci := range a.Loci {
+			if len(loci) != 2 {
+				err = fmt.Errorf("Attachment.Decode: mention %d loci must have a start and length", i)
+				return
+			}
+			m.Mentions = append(m.Mentions, Mention{UserID: a.UserIDs[i], Start: loci[0], Length: loci[1]})
+		}
+		v = m
+	case "split":
+		v = SplitAttachment{Token: a.Token}
+	case "emoji":
+		v = EmojiAttachment{Placeholder: a.Placeholder, Charmap: a.Charmap}
+	case "reply":
+		v = ReplyAttachment{ReplyID: a.ReplyID, BaseReplyID: a.BaseReplyID}
+	case "file":
+		v = FileAttachment{FileID: a.FileID}
+	case "video":
+		v = VideoAttachment{URL: a.URL, PreviewURL: a.PreviewURL}
+	default:
+		err = ErrUnknownAttachmentType
+	}
+	return
+}
+
+type Block struct {
+	UserID        string   `json:"user_id"`
+	BlockedUserID string   `json:"blocked_user_id"`
+	CreatedAt     UnixTime `json:"created_at"`
+}
+
+// A Mention is a user mentioned in a message's text. Start and Length are the
+// location of the mention within the text.
+type Mention struct {
+	UserID string
+	Start  int
+	Length int
+}
+
+// NewMentionAttachment creates a mentions attachment. A zero Attachment is
+// returned if any of the mention locations are negative, use
+// NewMentionAttachmentErr to get the reason.
+func NewMentionAttachment(mentions []Mention) Attachment {
+	a, _ := NewMentionAttachmentErr(mentions)
+	return a
+}
+
+// NewMentionAttachmentErr creates a mentions attachment. An error is returned if
+// any of the mention locations are negative.
+func NewMentionAttachmentErr(mentions []Mention) (a Attachment, err error) {
+	a.Type = "mentions"
+	for i, m := range mentions {
+		if m.Start < 0 || m.Length < 0 {
+			err = fmt.Errorf("NewMentionAttachment: mention %d has a negative start or length", i)
+			return Attachment{}, err
+		}
+		a.Loci = append(a.Loci, []int{m.Start, m.Length})
+		a.UserIDs = append(a.UserIDs, m.UserID)
+	}
+	return
+}
+
+type Bot struct {
+	BotID          string `json:"bot_id"`
+	GroupID        string `json:"group_id"`
+	Name           string `json:"name"`
+	AvatarURL      string `json:"avatar_url"`
+	CallbackURL    string `json:"callback_url"`
+	DmNotification bool   `json:"dm_notification"`
+}
+
+// UnmarshalJSON decodes a bot, accepting IDs encoded as either strings or
+// numbers.
+func (b *Bot) UnmarshalJSON(data []byte) (err error) {
+	type bot Bot // prevent recursion
+	v := struct {
+		*bot
+		BotID   flexString `json:"bot_id"`
+		GroupID flexString `json:"group_id"`
+	}{(*bot)(b), flexString(b.BotID), flexString(b.GroupID)}
+	err = json.Unmarshal(data, &v)
+	b.BotID, b.GroupID = string(v.BotID), string(v.GroupID)
+	return
+}
 
 type Charmap []uint64
 
+type Chat struct {
+	OtherUser struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	} `json:"other_user"`
+	LastMessage   DirectMessage `json:"last_message"`
+	MessagesCount int           `json:"messages_count"`
+	CreatedAt     UnixTime      `json:"created_at"`
+	UpdatedAt     UnixTime      `json:"updated_at"`
+}
+
+type DirectMessage struct {
+	ID             string       `json:"id"`
+	SourceGUID     string       `json:"source_guid"`
+	ConversationID string       `json:"conversation_id"`
+	RecipientID    string       `json:"recipient_id"`
+	UserID         string       `json:"user_id"`
+	CreatedAt      UnixTime     `json:"created_at"`
+	Name           string       `json:"name"`
+	AvatarURL      string       `json:"avatar_url"`
+	Text           string       `json:"text"`
+	FavoritedBy    []string     `json:"favorited_by"`
+	Attachments    []Attachment `json:"attachments"`
+}
+
+// UnmarshalJSON decodes a direct message, accepting IDs encoded as either
+// strings or numbers.
+func (m *DirectMessage) UnmarshalJSON(data []byte) (err error) {
+	type directMessage DirectMessage // prevent recursion
+	v := struct {
+		*directMessage
+		ID          flexString `json:"id"`
+		RecipientID flexString `json:"recipient_id"`
+		UserID      flexString `json:"user_id"`
+	}{(*directMessage)(m), flexString(m.ID), flexString(m.RecipientID), flexString(m.UserID)}
+	err = json.Unmarshal(data, &v)
+	m.ID, m.RecipientID, m.UserID = string(v.ID), string(v.RecipientID), string(v.UserID)
+	return
+}
+
 type Group struct {
 	ID            string   `json:"id"`
 	Name          string   `json:"name"`
@@ -53,7 +455,87 @@ type Group struct {
 	UpdatedAt     UnixTime `json:"updated_at"`
 	Members       []Member `json:"members"`
 	ShareURL      string   `json:"share_url"`
-	Messages      Messages `json:"messages"`
+
+	// Messages is left as the zero value by endpoints that omit it, in which
+	// case LastMessageCreatedAt.IsZero reports true.
+	Messages Messages `json:"messages"`
+}
+
+// UnmarshalJSON decodes a group, accepting IDs encoded as either strings or
+// numbers.
+func (g *Group) UnmarshalJSON(data []byte) (err error) {
+	type group Group // prevent recursion
+	v := struct {
+		*group
+		ID            flexString `json:"id"`
+		CreatorUserID flexString `json:"creator_user_id"`
+	}{(*group)(g), flexString(g.ID), flexString(g.CreatorUserID)}
+	err = json.Unmarshal(data, &v)
+	g.ID, g.CreatorUserID = string(v.ID), string(v.CreatorUserID)
+	return
+}
+
+// Types of groups returned in Group.Type.
+const (
+	GroupTypePrivate      = "private"
+	GroupTypePublic       = "public"
+	GroupTypeAnnouncement = "announcement"
+)
+
+// IsPrivate reports whether the group is private, i.e. can only be joined by
+// invitation or share URL.
+func (g Group) IsPrivate() bool { return g.Type == GroupTypePrivate }
+
+// IsPublic reports whether the group is public, i.e. can be found and joined by
+// anyone.
+func (g Group) IsPublic() bool { return g.Type == GroupTypePublic }
+
+// IsAnnouncement reports whether the group is an announcement group, in which
+// only admins can post.
+func (g Group) IsAnnouncement() bool { return g.Type == GroupTypeAnnouncement }
+
+// IsCreatedBy reports whether the group was created by the user with the given
+// ID. Only a group's creator can destroy it, so to check whether the
+// authenticated user can, compare with the ID returned by UsersService.Me:
+//
+//	me, err := users.Me(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	if group.IsCreatedBy(me.ID) {
+//		err = groups.Destroy(ctx, group.ID)
+//	}
+func (g Group) IsCreatedBy(userID string) bool {
+	return userID != "" && g.CreatorUserID == userID
+}
+
+// LastMessagePreview returns the preview of the group's most recent message. It
+// returns false if the group has no messages or the group was returned without
+// message details.
+func (g Group) LastMessagePreview() (preview Preview, ok bool) {
+	if g.Messages.LastMessageID == "" {
+		return
+	}
+	return g.Messages.Preview, true
+}
+
+// ShareToken returns the token from the group's share URL, which has the form
+// "https://groupme.com/join_group/{group ID}/{share token}", for use with
+// GroupsService.Join. It returns false if sharing is disabled for the group or
+// the share URL is malformed.
+func (g Group) ShareToken() (token string, ok bool) {
+	if g.ShareURL == "" {
+		return
+	}
+	u, err := url.Parse(g.ShareURL)
+	if err != nil {
+		return
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "join_group" || parts[2] == "" {
+		return
+	}
+	return parts[2], true
 }
 
 type Member struct {
@@ -61,6 +543,54 @@ type Member struct {
 	Nickname string `json:"nickname"`
 	Muted    bool   `json:"muted"`
 	ImageURL string `json:"image_url"`
+
+	// Fields used when adding members to a group.
+	PhoneNumber string `json:"phone_number,omitempty"`
+	Email       string `json:"email,omitempty"`
+	GUID        string `json:"guid,omitempty"`
+}
+
+// UnmarshalJSON decodes a member, accepting IDs encoded as either strings or
+// numbers.
+func (m *Member) UnmarshalJSON(data []byte) (err error) {
+	type member Member // prevent recursion
+	v := struct {
+		*member
+		UserID flexString `json:"user_id"`
+	}{(*member)(m), flexString(m.UserID)}
+	err = json.Unmarshal(data, &v)
+	m.UserID = string(v.UserID)
+	return
+}
+
+// MemberDiff compares two membership lists of a group by user ID, e.g. to sync
+// them with another system. It returns the members of new that aren't in old,
+// the members of old that aren't in new, and the members of new whose
+// nickname, muted state, or image URL differ from old. Added and changed
+// members are in the order of new, removed members are in the order of old.
+func MemberDiff(old, new []Member) (added, removed, changed []Member) {
+	oldByID := make(map[string]Member, len(old))
+	for _, m := range old {
+		oldByID[m.UserID] = m
+	}
+	newIDs := make(map[string]bool, len(new))
+	for _, m := range new {
+		newIDs[m.UserID] = true
+
+		o, ok := oldByID[m.UserID]
+		switch {
+		case !ok:
+			added = append(added, m)
+		case o.Nickname != m.Nickname || o.Muted != m.Muted || o.ImageURL != m.ImageURL:
+			changed = append(changed, m)
+		}
+	}
+	for _, m := range old {
+		if !newIDs[m.UserID] {
+			removed = append(removed, m)
+		}
+	}
+	return
 }
 
 type Message struct {
@@ -69,12 +599,205 @@ type Message struct {
 	CreatedAt   UnixTime     `json:"created_at"`
 	UserID      string       `json:"user_id"`
 	GroupID     string       `json:"group_id"`
+	SenderType  string       `json:"sender_type"`
+	SenderID    string       `json:"sender_id"`
 	Name        string       `json:"name"`
 	AvatarURL   string       `json:"avatar_url"`
 	Text        string       `json:"text"`
 	System      bool         `json:"system"`
 	FavoritedBy []string     `json:"favorited_by"`
 	Attachments []Attachment `json:"attachments"`
+	Reactions   []Reaction   `json:"reactions"`
+}
+
+// UnmarshalJSON decodes a message, accepting IDs encoded as either strings or
+// numbers.
+func (m *Message) UnmarshalJSON(data []byte) (err error) {
+	type message Message // prevent recursion
+	v := struct {
+		*message
+		ID       flexString `json:"id"`
+		UserID   flexString `json:"user_id"`
+		GroupID  flexString `json:"group_id"`
+		SenderID flexString `json:"sender_id"`
+	}{(*message)(m), flexString(m.ID), flexString(m.UserID), flexString(m.GroupID), flexString(m.SenderID)}
+	err = json.Unmarshal(data, &v)
+	m.ID, m.UserID, m.GroupID = string(v.ID), string(v.UserID), string(v.GroupID)
+	m.SenderID = string(v.SenderID)
+	return
+}
+
+// A ResolvedMention is a mention along with the text it covers in a message.
+type ResolvedMention struct {
+	UserID string
+	Text   string
+	Start  int
+	Length int
+}
+
+// Mentions returns the mentions from any mentions attachments of the message.
+// Mention locations are in characters rather than bytes, and any location
+// outside of the message text is clamped to it.
+func (m Message) Mentions() (mentions []ResolvedMention) {
+	text := []rune(m.Text)
+	for _, a := range m.Attachments {
+		if !a.IsTypeMentions() {
+			continue
+		}
+		for i, userID := range a.UserIDs {
+			if i >= len(a.Loci) || len(a.Loci[i]) < 2 {
+				break
+			}
+			start, length := a.Loci[i][0], a.Loci[i][1]
+			if start < 0 {
+				start = 0
+			}
+			if start > len(text) {
+				start = len(text)
+			}
+			if length < 0 {
+				length = 0
+			}
+			end := start + length
+			if end > len(text) {
+				end = len(text)
+			}
+			mentions = append(mentions, ResolvedMention{
+				UserID: userID,
+				Text:   string(text[start:end]),
+				Start:  start,
+				Length: end - start,
+			})
+		}
+	}
+	return
+}
+
+// A Reaction is an emoji reaction to a message, see ReactionsService. Legacy
+// likes are only included in Message.FavoritedBy.
+type Reaction struct {
+	// Type is "unicode" for reactions with a unicode emoji code, or "emoji"
+	// for reactions with an emoji from a pack, see EmojiRef.
+	Type      string   `json:"type"`
+	Code      string   `json:"code,omitempty"`
+	PackID    int      `json:"pack_id,omitempty"`
+	PackIndex int      `json:"pack_index,omitempty"`
+	UserIDs   []string `json:"user_ids"`
+}
+
+// Types of message senders returned in Message.SenderType.
+const (
+	SenderTypeUser   = "user"
+	SenderTypeBot    = "bot"
+	SenderTypeSystem = "system"
+)
+
+// IsFromBot reports whether the message was posted by a bot, in which case
+// SenderID is the bot's ID.
+func (m Message) IsFromBot() bool { return m.SenderType == SenderTypeBot }
+
+// LikedBy reports whether the user with the given ID has liked the message.
+func (m Message) LikedBy(userID string) bool {
+	for _, id := range m.FavoritedBy {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// LikeCount returns the number of users that have liked the message.
+func (m Message) LikeCount() int { return len(m.FavoritedBy) }
+
+// ImageURLs returns the URLs of the message's image attachments.
+func (m Message) ImageURLs() (urls []string) {
+	for _, a := range m.Attachments {
+		if a.IsTypeImage() {
+			urls = append(urls, a.URL)
+		}
+	}
+	return
+}
+
+// LocationAttachments returns the message's location attachments.
+func (m Message) LocationAttachments() []Attachment {
+	return m.attachmentsOfType("location")
+}
+
+// MentionAttachments returns the message's mentions attachments. See Mentions
+// for the mentions they contain.
+func (m Message) MentionAttachments() []Attachment {
+	return m.attachmentsOfType("mentions")
+}
+
+func (m Message) attachmentsOfType(typ string) (attachments []Attachment) {
+	for _, a := range m.Attachments {
+		if a.Type == typ {
+			attachments = append(attachments, a)
+		}
+	}
+	return
+}
+
+// Types of system events returned by Message.SystemEvent.
+const (
+	SystemEventMemberAdded   = "member_added"
+	SystemEventMemberRemoved = "member_removed"
+	SystemEventNameChanged   = "name_changed"
+	SystemEventAvatarChanged = "avatar_changed"
+)
+
+// A SystemEvent is an event described by a system message, e.g. a member being
+// added to the group.
+type SystemEvent struct {
+	// Type is one of the SystemEvent constants.
+	Type string
+
+	// Actor is the name of the user that caused the event.
+	Actor string
+
+	// Members are the names of the members added or removed.
+	Members []string
+
+	// Name is the new group name.
+	Name string
+}
+
+var (
+	systemMemberAddedRegexp   = regexp.MustCompile(`^(.+?) added (.+) to the group\.?$`)
+	systemMemberRemovedRegexp = regexp.MustCompile(`^(.+?) removed (.+) from the group\.?$`)
+	systemNameChangedRegexp   = regexp.MustCompile(`^(.+?) changed the group's name to (.+)$`)
+	systemAvatarChangedRegexp = regexp.MustCompile(`^(.+?) changed the group's avatar\.?$`)
+)
+
+// SystemEvent parses the event described by a system message's text. It returns
+// false if the message isn't a system message or the event isn't recognized.
+func (m Message) SystemEvent() (event SystemEvent, ok bool) {
+	if !m.System {
+		return
+	}
+
+	if match := systemMemberAddedRegexp.FindStringSubmatch(m.Text); match != nil {
+		event = SystemEvent{Type: SystemEventMemberAdded, Actor: match[1], Members: splitNames(match[2])}
+	} else if match := systemMemberRemovedRegexp.FindStringSubmatch(m.Text); match != nil {
+		event = SystemEvent{Type: SystemEventMemberRemoved, Actor: match[1], Members: splitNames(match[2])}
+	} else if match := systemNameChangedRegexp.FindStringSubmatch(m.Text); match != nil {
+		event = SystemEvent{Type: SystemEventNameChanged, Actor: match[1], Name: match[2]}
+	} else if match := systemAvatarChangedRegexp.FindStringSubmatch(m.Text); match != nil {
+		event = SystemEvent{Type: SystemEventAvatarChanged, Actor: match[1]}
+	} else {
+		return
+	}
+	return event, true
+}
+
+// splitNames splits a list of names such as "A", "A and B", or "A, B, and C".
+func splitNames(s string) (names []string) {
+	for _, part := range strings.Split(s, ", ") {
+		part = strings.TrimPrefix(part, "and ")
+		names = append(names, strings.Split(part, " and ")...)
+	}
+	return
 }
 
 type Messages struct {
@@ -85,32 +808,180 @@ type Messages struct {
 }
 
 type Preview struct {
-	Nickname    string       `json:"nickname"`
-	Text        string       `json:"text"`
+	Nickname string `json:"nickname"`
+	Text     string `json:"text"`
+
+	// ImageURL is the avatar of the message's sender, see AttachedImageURL for
+	// the message's image.
 	ImageURL    string       `json:"image_url"`
 	Attachments []Attachment `json:"attachments"`
 }
 
+// HasImage reports whether the previewed message has an image attachment.
+func (p Preview) HasImage() bool {
+	_, ok := p.attachedImage()
+	return ok
+}
+
+// AttachedImageURL returns the URL of the previewed message's first image
+// attachment, e.g. to show a thumbnail in a list of groups. An empty string is
+// returned if it has none.
+func (p Preview) AttachedImageURL() string {
+	a, _ := p.attachedImage()
+	return a.URL
+}
+
+func (p Preview) attachedImage() (a Attachment, ok bool) {
+	for _, a := range p.Attachments {
+		if a.IsTypeImage() && a.URL != "" {
+			return a, true
+		}
+	}
+	return
+}
+
+type User struct {
+	ID          string   `json:"id"`
+	PhoneNumber string   `json:"phone_number"`
+	ImageURL    string   `json:"image_url"`
+	Name        string   `json:"name"`
+	CreatedAt   UnixTime `json:"created_at"`
+	UpdatedAt   UnixTime `json:"updated_at"`
+	Email       string   `json:"email"`
+	SMS         bool     `json:"sms"`
+	Zip         string   `json:"zip_code"`
+}
+
+// UnmarshalJSON decodes a user, accepting IDs encoded as either strings or
+// numbers.
+func (u *User) UnmarshalJSON(data []byte) (err error) {
+	type user User // prevent recursion
+	v := struct {
+		*user
+		ID flexString `json:"id"`
+	}{(*user)(u), flexString(u.ID)}
+	err = json.Unmarshal(data, &v)
+	u.ID = string(v.ID)
+	return
+}
+
+// A UnixTime is a time encoded in JSON as seconds since the Unix epoch. Decoded
+// times are in the local time zone, use InLocation for another. The encoding
+// does not depend on the time zone.
 type UnixTime struct {
 	time.Time
 }
 
+// InLocation returns the time in the given location.
+func (t UnixTime) InLocation(loc *time.Location) time.Time {
+	return t.Time.In(loc)
+}
+
+// IsZero reports whether the time is unset, i.e. was decoded from null or an
+// empty value. A time at the Unix epoch, decoded from 0, is not zero.
+func (t UnixTime) IsZero() bool {
+	return t.Time.IsZero()
+}
+
 var (
 	_ json.Marshaler   = (*UnixTime)(nil)
 	_ json.Unmarshaler = (*UnixTime)(nil)
 )
 
-func (t *UnixTime) MarshalJSON() (data []byte, err error) {
-	data = []byte(fmt.Sprintf("%d", t.Unix()))
+// MarshalJSON encodes the time as seconds since the Unix epoch, including any
+// fractional seconds. A zero time is encoded as null.
+func (t UnixTime) MarshalJSON() (data []byte, err error) {
+	if t.IsZero() {
+		data = []byte("null")
+		return
+	}
+
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+	if nsec == 0 {
+		data = []byte(strconv.FormatInt(sec, 10))
+		return
+	}
+
+	sign := ""
+	if sec < 0 {
+		sign = "-"
+		sec, nsec = -(sec + 1), 1e9-nsec
+	}
+	frac := strings.TrimRight(fmt.Sprintf("%09d", nsec), "0")
+	data = []byte(fmt.Sprintf("%s%d.%s", sign, sec, frac))
 	return
 }
 
+// UnmarshalJSON decodes the time from seconds since the Unix epoch, encoded as
+// either an integer or a floating point number. A null or empty value is
+// decoded as the zero time.
 func (t *UnixTime) UnmarshalJSON(data []byte) (err error) {
-	var sec int64
-	sec, err = strconv.ParseInt(string(data), 10, 64)
+	s := string(data)
+	if s == "null" || s == "" || s == `""` {
+		(*t).Time = time.Time{}
+		return
+	}
+
+	var sec, nsec int64
+	if strings.ContainsAny(s, "eE") {
+		var f float64
+		f, err = strconv.ParseFloat(s, 64)
+		if err != nil {
+			return
+		}
+		whole, frac := math.Modf(f)
+		sec, nsec = int64(whole), int64(frac*1e9)
+	} else if i := strings.IndexByte(s, '.'); i >= 0 {
+		sec, err = strconv.ParseInt(s[:i], 10, 64)
+		if err != nil {
+			return
+		}
+		frac := s[i+1:]
+		if frac == "" || strings.TrimLeft(frac, "0123456789") != "" {
+			err = fmt.Errorf("UnixTime.UnmarshalJSON: invalid time '%s'", s)
+			return
+		}
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		frac += strings.Repeat("0", 9-len(frac))
+		nsec, err = strconv.ParseInt(frac, 10, 64)
+		if err != nil {
+			return
+		}
+		if strings.HasPrefix(s, "-") {
+			nsec = -nsec
+		}
+	} else {
+		sec, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return
+		}
+	}
+	(*t).Time = time.Unix(sec, nsec)
+	return
+}
+
+// A flexString is a string that may be encoded in JSON as either a string or a
+// number. The API is inconsistent in how it encodes IDs, which are usually
+// strings but are numbers in some responses.
+type flexString string
+
+// UnmarshalJSON decodes the string from a JSON string or number. A null value
+// leaves the string unchanged.
+func (s *flexString) UnmarshalJSON(data []byte) (err error) {
+	if string(data) == "null" {
+		return
+	}
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, (*string)(s))
+	}
+
+	var n json.Number
+	err = json.Unmarshal(data, &n)
 	if err != nil {
 		return
 	}
-	(*t).Time = time.Unix(sec, 0)
+	*s = flexString(n)
 	return
 }
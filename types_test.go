@@ -0,0 +1,289 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestAttachmentMarshalJSON(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Attachment Attachment
+		Expected   string
+	}{
+		{
+			Name: "Image",
+			Attachment: Attachment{
+				Type:  "image",
+				URL:   "https://i.groupme.com/123456789",
+				Loci:  [][]int{{0, 5}},
+				Token: "TOKEN",
+			},
+			Expected: `{"type":"image","url":"https://i.groupme.com/123456789"}`,
+		},
+		{
+			Name:       "Location",
+			Attachment: Attachment{Type: "location", Lat: "40.738206", Lng: "-73.993285", Name: "GroupMe HQ", URL: "https://example.com"},
+			Expected:   `{"type":"location","lat":"40.738206","lng":"-73.993285","name":"GroupMe HQ"}`,
+		},
+		{
+			Name:       "Mentions",
+			Attachment: Attachment{Type: "mentions", Loci: [][]int{{0, 5}, {10, 4}}, UserIDs: []string{"1", "2"}, Name: "Jane"},
+			Expected:   `{"type":"mentions","loci":[[0,5],[10,4]],"user_ids":["1","2"]}`,
+		},
+		{
+			Name:       "Split",
+			Attachment: Attachment{Type: "split", Token: "SPLIT_TOKEN", FileID: "1"},
+			Expected:   `{"type":"split","token":"SPLIT_TOKEN"}`,
+		},
+		{
+			Name:       "Emoji",
+			Attachment: Attachment{Type: "emoji", Placeholder: "☃", Charmap: []Charmap{{1, 42}}, UserIDs: []string{"1"}},
+			Expected:   `{"type":"emoji","placeholder":"☃","charmap":[[1,42]]}`,
+		},
+		{
+			Name:       "Reply",
+			Attachment: Attachment{Type: "reply", ReplyID: "2", BaseReplyID: "1", URL: "https://example.com"},
+			Expected:   `{"type":"reply","reply_id":"2","base_reply_id":"1"}`,
+		},
+		{
+			Name:       "File",
+			Attachment: Attachment{Type: "file", FileID: "FILE_ID", Token: "TOKEN"},
+			Expected:   `{"type":"file","file_id":"FILE_ID"}`,
+		},
+		{
+			Name:       "Video",
+			Attachment: Attachment{Type: "video", URL: "https://v.groupme.com/1.mp4", PreviewURL: "https://v.groupme.com/1.jpeg", Lat: "1"},
+			Expected:   `{"type":"video","url":"https://v.groupme.com/1.mp4","preview_url":"https://v.groupme.com/1.jpeg"}`,
+		},
+		{
+			Name:       "Unknown",
+			Attachment: Attachment{Type: "poll", URL: "https://example.com", Token: "TOKEN"},
+			Expected:   `{"type":"poll","url":"https://example.com","token":"TOKEN"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			b, err := json.Marshal(test.Attachment)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(b) != test.Expected {
+				t.Errorf("expected %s but got %s", test.Expected, b)
+			}
+		})
+	}
+}
+
+func TestUnmarshalFlexibleIDs(t *testing.T) {
+	tests := []struct {
+		Name   string
+		String string
+		Number string
+		Decode func(data string) (ids []string, err error)
+	}{
+		{
+			Name:   "Bot",
+			String: `{"bot_id":"1","group_id":"2"}`,
+			Number: `{"bot_id":1,"group_id":2}`,
+			Decode: func(data string) ([]string, error) {
+				var v Bot
+				err := json.Unmarshal([]byte(data), &v)
+				return []string{v.BotID, v.GroupID}, err
+			},
+		},
+		{
+			Name:   "DirectMessage",
+			String: `{"id":"1","recipient_id":"2","user_id":"3"}`,
+			Number: `{"id":1,"recipient_id":2,"user_id":3}`,
+			Decode: func(data string) ([]string, error) {
+				var v DirectMessage
+				err := json.Unmarshal([]byte(data), &v)
+				return []string{v.ID, v.RecipientID, v.UserID}, err
+			},
+		},
+		{
+			Name:   "Group",
+			String: `{"id":"1","creator_user_id":"2"}`,
+			Number: `{"id":1,"creator_user_id":2}`,
+			Decode: func(data string) ([]string, error) {
+				var v Group
+				err := json.Unmarshal([]byte(data), &v)
+				return []string{v.ID, v.CreatorUserID}, err
+			},
+		},
+		{
+			Name:   "Member",
+			String: `{"user_id":"1"}`,
+			Number: `{"user_id":1}`,
+			Decode: func(data string) ([]string, error) {
+				var v Member
+				err := json.Unmarshal([]byte(data), &v)
+				return []string{v.UserID}, err
+			},
+		},
+		{
+			Name:   "Message",
+			String: `{"id":"1","user_id":"2","group_id":"3","sender_id":"4"}`,
+			Number: `{"id":1,"user_id":2,"group_id":3,"sender_id":4}`,
+			Decode: func(data string) ([]string, error) {
+				var v Message
+				err := json.Unmarshal([]byte(data), &v)
+				return []string{v.ID, v.UserID, v.GroupID, v.SenderID}, err
+			},
+		},
+		{
+			Name:   "User",
+			String: `{"id":"1"}`,
+			Number: `{"id":1}`,
+			Decode: func(data string) ([]string, error) {
+				var v User
+				err := json.Unmarshal([]byte(data), &v)
+				return []string{v.ID}, err
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			for _, data := range []string{test.String, test.Number} {
+				ids, err := test.Decode(data)
+				if err != nil {
+					t.Fatalf("unexpected error decoding %s: %v", data, err)
+				}
+				for i, id := range ids {
+					if expected := string(rune('1' + i)); id != expected {
+						t.Errorf("decoding %s: expected ID %d to be '%s' but got '%s'", data, i, expected, id)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFlexStringUnmarshalJSON(t *testing.T) {
+	var v struct {
+		ID flexString `json:"id"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":12345678901234567890}`), &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.ID != "12345678901234567890" {
+		t.Errorf("expected large number to be decoded exactly but got '%s'", v.ID)
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":null}`), &v); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.ID != "12345678901234567890" {
+		t.Errorf("expected null to leave the previous value but got '%s'", v.ID)
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":true}`), &v); err == nil {
+		t.Errorf("expected error decoding a bool")
+	}
+
+	m := Message{ID: "1", GroupID: "2"}
+	if err := json.Unmarshal([]byte(`{"id":null,"text":"Hello"}`), &m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.ID != "1" || m.GroupID != "2" {
+		t.Errorf("expected null and missing IDs to leave the previous values but got '%s' and '%s'", m.ID, m.GroupID)
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":false}`), &m); err == nil {
+		t.Errorf("expected error decoding a message with a bool ID")
+	}
+}
+
+func TestUnixTimeMarshalJSON(t *testing.T) {
+	tests := []struct {
+		Time     UnixTime
+		Expected string
+	}{
+		{UnixTime{}, `null`},
+		{UnixTime{time.Unix(0, 0)}, `0`},
+		{UnixTime{time.Unix(1500000000, 0)}, `1500000000`},
+		{UnixTime{time.Unix(1500000000, 250e6)}, `1500000000.25`},
+		{UnixTime{time.Unix(0, 1)}, `0.000000001`},
+		{UnixTime{time.Unix(-2, 500e6)}, `-1.5`},
+		{UnixTime{time.Unix(-1, 500e6)}, `-0.5`},
+		{UnixTime{time.Unix(-1, 0)}, `-1`},
+	}
+
+	for _, test := range tests {
+		b, err := json.Marshal(test.Time)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", test.Time, err)
+		}
+		if string(b) != test.Expected {
+			t.Errorf("%v: expected %s but got %s", test.Time, test.Expected, b)
+		}
+
+		// Every encoding decodes to the same time
+		var decoded UnixTime
+		if err := json.Unmarshal(b, &decoded); err != nil {
+			t.Fatalf("%s: unexpected error decoding: %v", b, err)
+		}
+		if !decoded.Equal(test.Time.Time) {
+			t.Errorf("%s: expected to decode %v but got %v", b, test.Time, decoded)
+		}
+	}
+}
+
+func TestUnixTimeUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		Data     string
+		Expected time.Time // zero if expecting the zero time or an error
+		Err      bool
+	}{
+		{Data: `null`},
+		{Data: ``},
+		{Data: `""`},
+		{Data: `0`, Expected: time.Unix(0, 0)},
+		{Data: `1500000000`, Expected: time.Unix(1500000000, 0)},
+		{Data: `1500000000.25`, Expected: time.Unix(1500000000, 250e6)},
+		{Data: `1500000000.1234567891`, Expected: time.Unix(1500000000, 123456789)},
+		{Data: `-1.5`, Expected: time.Unix(-2, 500e6)},
+		{Data: `-0.5`, Expected: time.Unix(-1, 500e6)},
+		{Data: `1.5e9`, Expected: time.Unix(1500000000, 0)},
+		{Data: `1.5E0`, Expected: time.Unix(1, 500e6)},
+		{Data: `-1.5e0`, Expected: time.Unix(-2, 500e6)},
+		{Data: `15e-1`, Expected: time.Unix(1, 500e6)},
+		{Data: `"1500000000"`, Err: true},
+		{Data: `true`, Err: true},
+		{Data: `1.5.5`, Err: true},
+		{Data: `1.-5`, Err: true},
+		{Data: `1e`, Err: true},
+	}
+
+	for _, test := range tests {
+		// Start from a non-zero time to check it is replaced
+		v := UnixTime{time.Unix(1, 0)}
+		err := v.UnmarshalJSON([]byte(test.Data))
+		if test.Err {
+			if err == nil {
+				t.Errorf("'%s': expected error but got %v", test.Data, v)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("'%s': unexpected error: %v", test.Data, err)
+			continue
+		}
+		if test.Expected.IsZero() {
+			if !v.IsZero() {
+				t.Errorf("'%s': expected the zero time but got %v", test.Data, v)
+			}
+		} else if !v.Equal(test.Expected) {
+			t.Errorf("'%s': expected %v but got %v", test.Data, test.Expected, v)
+		}
+	}
+}
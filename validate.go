@@ -0,0 +1,241 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// A schema describes a JSON value accepted by the API.
+type schema struct {
+	kind     string // "string", "bool", "object", "array", or "any"
+	required bool
+	maxLen   int               // maximum length of a string in characters, if non-zero
+	fields   map[string]schema // fields of an object
+	elem     *schema           // elements of an array
+}
+
+var (
+	anySchema    = schema{kind: "any"}
+	boolSchema   = schema{kind: "bool"}
+	stringSchema = schema{kind: "string"}
+)
+
+func objectSchema(fields map[string]schema) schema {
+	return schema{kind: "object", fields: fields}
+}
+
+func requiredSchema(s schema) schema {
+	s.required = true
+	return s
+}
+
+func maxLenSchema(n int) schema {
+	return schema{kind: "string", maxLen: n}
+}
+
+func arraySchema(elem schema) schema {
+	return schema{kind: "array", elem: &elem}
+}
+
+// requestSchemas are the documented request bodies of the endpoints used by
+// the services, keyed by method and path pattern relative to the base URL.
+var requestSchemas = map[string]schema{
+	"POST /groups": objectSchema(map[string]schema{
+		"name":        requiredSchema(maxLenSchema(140)),
+		"description": maxLenSchema(255),
+		"image_url":   stringSchema,
+		"type":        stringSchema,
+		"share":       boolSchema,
+	}),
+	"POST /groups/*/update": objectSchema(map[string]schema{
+		"name":        maxLenSchema(140),
+		"description": maxLenSchema(255),
+		"image_url":   stringSchema,
+		"share":       boolSchema,
+		"office_mode": boolSchema,
+	}),
+	"POST /groups/*/members/add": objectSchema(map[string]schema{
+		"members": requiredSchema(arraySchema(objectSchema(map[string]schema{
+			"nickname":     stringSchema,
+			"user_id":      stringSchema,
+			"phone_number": stringSchema,
+			"email":        stringSchema,
+			"guid":         stringSchema,
+		}))),
+	}),
+	"POST /groups/*/memberships/update": objectSchema(map[string]schema{
+		"membership": requiredSchema(objectSchema(map[string]schema{
+			"nickname": stringSchema,
+			"muted":    boolSchema,
+		})),
+	}),
+	"POST /groups/*/messages": objectSchema(map[string]schema{
+		"message": requiredSchema(objectSchema(map[string]schema{
+			"source_guid": requiredSchema(stringSchema),
+			"text":        maxLenSchema(1000),
+			"attachments": arraySchema(anySchema),
+		})),
+	}),
+	"POST /direct_messages": objectSchema(map[string]schema{
+		"direct_message": requiredSchema(objectSchema(map[string]schema{
+			"source_guid":  requiredSchema(stringSchema),
+			"recipient_id": requiredSchema(stringSchema),
+			"text":         maxLenSchema(1000),
+			"attachments":  arraySchema(anySchema),
+		})),
+	}),
+	"POST /bots": objectSchema(map[string]schema{
+		"bot": requiredSchema(objectSchema(map[string]schema{
+			"name":            requiredSchema(stringSchema),
+			"group_id":        requiredSchema(stringSchema),
+			"avatar_url":      stringSchema,
+			"callback_url":    stringSchema,
+			"dm_notification": boolSchema,
+		})),
+	}),
+	"POST /bots/post": objectSchema(map[string]schema{
+		"bot_id":      requiredSchema(stringSchema),
+		"text":        maxLenSchema(1000),
+		"picture_url": stringSchema,
+		"attachments": arraySchema(anySchema),
+	}),
+	"POST /users/update": objectSchema(map[string]schema{
+		"avatar_url": stringSchema,
+		"name":       stringSchema,
+		"email":      stringSchema,
+		"zip_code":   stringSchema,
+	}),
+}
+
+// validateRequest checks the JSON body of a request to an endpoint with a known
+// schema, returning an error for unknown fields, missing required fields, and
+// values of the wrong type or length. The body of the request is restored
+// after being read.
+func validateRequest(req *http.Request, baseURL string) (err error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil || req.URL.Host != base.Host || !strings.HasPrefix(req.URL.Path, base.Path) {
+		return nil
+	}
+	endpoint := strings.TrimPrefix(req.URL.Path, base.Path)
+
+	var sc schema
+	var found bool
+	for pattern, s := range requestSchemas {
+		method, p := splitPattern(pattern)
+		if ok, _ := path.Match(p, endpoint); ok && method == req.Method {
+			sc, found = s, true
+			break
+		}
+	}
+	if !found {
+		return
+	}
+
+	var body []byte
+	body, err = ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var v interface{}
+	if err = json.Unmarshal(body, &v); err != nil {
+		return fmt.Errorf("Client.Do: invalid request body for %s %s: %v", req.Method, endpoint, err)
+	}
+	if err = sc.validate("", v); err != nil {
+		return fmt.Errorf("Client.Do: invalid request body for %s %s: %v", req.Method, endpoint, err)
+	}
+	return
+}
+
+func splitPattern(pattern string) (method, path string) {
+	i := strings.IndexByte(pattern, ' ')
+	return pattern[:i], pattern[i+1:]
+}
+
+func (s schema) validate(name string, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+
+	display := name
+	if display == "" {
+		display = "body"
+	}
+
+	switch s.kind {
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("field '%s' must be a string", display)
+		}
+		if s.maxLen != 0 && utf8.RuneCountInString(str) > s.maxLen {
+			return fmt.Errorf("field '%s' length maximum is %d characters", display, s.maxLen)
+		}
+	case "bool":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("field '%s' must be a boolean", display)
+		}
+	case "array":
+		elems, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("field '%s' must be an array", display)
+		}
+		for i, e := range elems {
+			if err := s.elem.validate(fmt.Sprintf("%s[%d]", name, i), e); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("field '%s' must be an object", display)
+		}
+
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fieldName := k
+			if name != "" {
+				fieldName = name + "." + k
+			}
+			f, ok := s.fields[k]
+			if !ok {
+				return fmt.Errorf("unknown field '%s'", fieldName)
+			}
+			if err := f.validate(fieldName, obj[k]); err != nil {
+				return err
+			}
+		}
+
+		for k, f := range s.fields {
+			if _, ok := obj[k]; f.required && !ok {
+				fieldName := k
+				if name != "" {
+					fieldName = name + "." + k
+				}
+				return fmt.Errorf("field '%s' is required", fieldName)
+			}
+		}
+	}
+	return nil
+}
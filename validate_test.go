@@ -0,0 +1,182 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const testValidateBaseURL = "https://api.groupme.com/v3"
+
+func newValidateRequest(t *testing.T, method, path, body string) *http.Request {
+	req, err := http.NewRequest(method, testValidateBaseURL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return req
+}
+
+func TestValidateRequestSchemas(t *testing.T) {
+	tests := []struct {
+		Pattern string // key of requestSchemas
+		Path    string
+		Valid   string
+		Unknown string // a body with an unknown field
+	}{
+		{
+			Pattern: "POST /groups",
+			Path:    "/groups",
+			Valid:   `{"name":"Family","description":"Coolest Family Ever","share":true}`,
+			Unknown: `{"name":"Family","office_mode":true}`,
+		},
+		{
+			Pattern: "POST /groups/*/update",
+			Path:    "/groups/1/update",
+			Valid:   `{"name":"Family","office_mode":false}`,
+			Unknown: `{"name":"Family","type":"private"}`,
+		},
+		{
+			Pattern: "POST /groups/*/members/add",
+			Path:    "/groups/1/members/add",
+			Valid:   `{"members":[{"nickname":"Jane","user_id":"1","guid":"GUID"}]}`,
+			Unknown: `{"members":[{"nickname":"Jane","muted":true}]}`,
+		},
+		{
+			Pattern: "POST /groups/*/memberships/update",
+			Path:    "/groups/1/memberships/update",
+			Valid:   `{"membership":{"nickname":"Jane","muted":true}}`,
+			Unknown: `{"membership":{"nickname":"Jane"},"user_id":"1"}`,
+		},
+		{
+			Pattern: "POST /groups/*/messages",
+			Path:    "/groups/1/messages",
+			Valid:   `{"message":{"source_guid":"GUID","text":"Hello","attachments":[{"type":"image","url":"https://i.groupme.com/1"}]}}`,
+			Unknown: `{"message":{"source_guid":"GUID","text":"Hello","picture_url":"https://i.groupme.com/1"}}`,
+		},
+		{
+			Pattern: "POST /direct_messages",
+			Path:    "/direct_messages",
+			Valid:   `{"direct_message":{"source_guid":"GUID","recipient_id":"1","text":"Hello"}}`,
+			Unknown: `{"direct_message":{"source_guid":"GUID","recipient_id":"1","group_id":"2"}}`,
+		},
+		{
+			Pattern: "POST /bots",
+			Path:    "/bots",
+			Valid:   `{"bot":{"name":"Bot","group_id":"1","callback_url":"https://example.com","dm_notification":true}}`,
+			Unknown: `{"bot":{"name":"Bot","group_id":"1","bot_id":"2"}}`,
+		},
+		{
+			Pattern: "POST /bots/post",
+			Path:    "/bots/post",
+			Valid:   `{"bot_id":"1","text":"Hello","picture_url":"https://i.groupme.com/1"}`,
+			Unknown: `{"bot_id":"1","text":"Hello","group_id":"2"}`,
+		},
+		{
+			Pattern: "POST /users/update",
+			Path:    "/users/update",
+			Valid:   `{"name":"Jane","zip_code":"10001"}`,
+			Unknown: `{"name":"Jane","phone_number":"+1 2125551234"}`,
+		},
+	}
+
+	tested := make(map[string]bool)
+	for _, test := range tests {
+		tested[test.Pattern] = true
+		t.Run(test.Pattern, func(t *testing.T) {
+			method, _ := splitPattern(test.Pattern)
+
+			req := newValidateRequest(t, method, test.Path, test.Valid)
+			if err := validateRequest(req, testValidateBaseURL); err != nil {
+				t.Errorf("unexpected error validating %s: %v", test.Valid, err)
+			}
+
+			// The body can still be sent once validated
+			b, err := ioutil.ReadAll(req.Body)
+			if err != nil || string(b) != test.Valid {
+				t.Errorf("expected body to be restored but got '%s' %v", b, err)
+			}
+
+			req = newValidateRequest(t, method, test.Path, test.Unknown)
+			if err := validateRequest(req, testValidateBaseURL); err == nil || !strings.Contains(err.Error(), "unknown field") {
+				t.Errorf("expected unknown field error validating %s but got %v", test.Unknown, err)
+			}
+		})
+	}
+
+	for pattern := range requestSchemas {
+		if !tested[pattern] {
+			t.Errorf("schema '%s' is not tested", pattern)
+		}
+	}
+}
+
+func TestValidateRequestErrors(t *testing.T) {
+	tests := []struct {
+		Body     string
+		Expected string
+	}{
+		{`{"message":{"text":"Hello"}}`, "field 'message.source_guid' is required"},
+		{`{"message":{"source_guid":1}}`, "field 'message.source_guid' must be a string"},
+		{`{"message":{"source_guid":"GUID","text":"` + strings.Repeat("☃", 1001) + `"}}`, "field 'message.text' length maximum is 1000 characters"},
+		{`{"message":{"source_guid":"GUID","attachments":{}}}`, "field 'message.attachments' must be an array"},
+		{`{"message":"Hello"}`, "field 'message' must be an object"},
+		{`not json`, "invalid request body"},
+	}
+
+	for _, test := range tests {
+		req := newValidateRequest(t, http.MethodPost, "/groups/1/messages", test.Body)
+		if err := validateRequest(req, testValidateBaseURL); err == nil || !strings.Contains(err.Error(), test.Expected) {
+			t.Errorf("%s: expected error containing '%s' but got %v", test.Body, test.Expected, err)
+		}
+	}
+
+	// Text at the limit is accepted, since it is counted in characters
+	req := newValidateRequest(t, http.MethodPost, "/groups/1/messages", `{"message":{"source_guid":"GUID","text":"`+strings.Repeat("☃", 1000)+`"}}`)
+	if err := validateRequest(req, testValidateBaseURL); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateRequestEndpoint(t *testing.T) {
+	const unknown = `{"unknown":true}`
+
+	tests := []struct {
+		Name      string
+		Method    string
+		URL       string
+		Validated bool
+	}{
+		{"Match", http.MethodPost, testValidateBaseURL + "/groups/1/messages", true},
+		{"MatchExact", http.MethodPost, testValidateBaseURL + "/groups", true},
+		{"OtherMethod", http.MethodGet, testValidateBaseURL + "/groups", false},
+		{"WildcardCrossesSlash", http.MethodPost, testValidateBaseURL + "/groups/1/2/messages", false},
+		{"Subpath", http.MethodPost, testValidateBaseURL + "/groups/1/messages/2", false},
+		{"Unknown", http.MethodPost, testValidateBaseURL + "/groups/1/destroy", false},
+		{"OutsideBasePath", http.MethodPost, "https://api.groupme.com/groups", false},
+		{"OtherHost", http.MethodPost, "https://image.groupme.com/v3/groups", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := http.NewRequest(test.Method, test.URL, strings.NewReader(unknown))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = validateRequest(req, testValidateBaseURL)
+			if validated := err != nil; validated != test.Validated {
+				t.Errorf("expected validated to be %v but got error %v", test.Validated, err)
+			}
+		})
+	}
+
+	// Requests without a body aren't validated
+	req, _ := http.NewRequest(http.MethodPost, testValidateBaseURL+"/groups", nil)
+	if err := validateRequest(req, testValidateBaseURL); err != nil {
+		t.Errorf("unexpected error validating a request without a body: %v", err)
+	}
+}
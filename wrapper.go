@@ -0,0 +1,51 @@
+// Copyright 2018 Josh Lubawy. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package groupme
+
+import (
+	"context"
+)
+
+// A clientWrapper is embedded by clients that wrap another client, e.g.
+// RetryClient, forwarding the optional client methods to the wrapped client.
+type clientWrapper struct {
+	client Client
+}
+
+// BaseURL returns the base URL of the wrapped client.
+func (w clientWrapper) BaseURL() string { return baseURL(w.client) }
+
+// ImageServiceURL returns the image service URL of the wrapped client.
+func (w clientWrapper) ImageServiceURL() string { return imageServiceURL(w.client) }
+
+// PushURL returns the push service URL of the wrapped client.
+func (w clientWrapper) PushURL() string { return pushURL(w.client) }
+
+// LastRateLimit returns the rate limit recorded by the wrapped client, if it
+// records one.
+func (w clientWrapper) LastRateLimit() (rl RateLimit) {
+	if r, ok := w.client.(RateLimitReporter); ok {
+		rl = r.LastRateLimit()
+	}
+	return
+}
+
+// SetAccessToken sets the access token of the wrapped client, if it supports
+// changing it.
+func (w clientWrapper) SetAccessToken(accessToken string) {
+	if s, ok := w.client.(AccessTokenSetter); ok {
+		s.SetAccessToken(accessToken)
+	}
+}
+
+// withContext returns a wrapper of the wrapped client bound to the given
+// context, if it supports binding one, see ContextBinder. It is used by the
+// WithContext methods of the wrapping clients.
+func (w clientWrapper) withContext(ctx context.Context) clientWrapper {
+	if b, ok := w.client.(ContextBinder); ok {
+		return clientWrapper{client: b.WithContext(ctx)}
+	}
+	return w
+}